@@ -3,10 +3,15 @@ package database
 import (
 	"database/sql"
 	"log"
+	"os"
+	"time"
 
 	_ "github.com/lib/pq" // Importing the PostgreSQL driver for Go
 )
 
+// defaultQueryTimeout is used when DB_QUERY_TIMEOUT is not set.
+const defaultQueryTimeout = 5 * time.Second
+
 func NewPostgresDB() *sql.DB {
 	connStr := "user=postgres dbname=inventaris sslmode=disable password=postgres host=localhost"
 	db, err := sql.Open("postgres", connStr)
@@ -20,3 +25,19 @@ func NewPostgresDB() *sql.DB {
 
 	return db
 }
+
+// QueryTimeout reads the per-query timeout from DB_QUERY_TIMEOUT (for example
+// "3s" or "500ms"). Setting it to "0" disables the timeout.
+func QueryTimeout() time.Duration {
+	value := os.Getenv("DB_QUERY_TIMEOUT")
+	if value == "" {
+		return defaultQueryTimeout
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid DB_QUERY_TIMEOUT %q, using %v: %v", value, defaultQueryTimeout, err.Error())
+		return defaultQueryTimeout
+	}
+	return timeout
+}
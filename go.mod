@@ -3,8 +3,8 @@ module github.com/Safiramdhn/project-app-inventaris-golang-safira
 go 1.22.1
 
 require (
-	github.com/go-chi/chi/v5 v5.1.0 // indirect
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/lib/pq v1.10.9 // indirect
-	golang.org/x/crypto v0.29.0 // indirect
+	github.com/go-chi/chi/v5 v5.1.0
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.10.9
+	golang.org/x/crypto v0.29.0
 )
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+)
+
+type ArchiveHandler struct {
+	ArchiveService *services.ArchiveService
+}
+
+func NewArchiveHandler(archiveService *services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{ArchiveService: archiveService}
+}
+
+func (ah *ArchiveHandler) PurgeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	retentionDays := models.DefaultPurgeRetentionDays
+	if value := r.URL.Query().Get("retention_days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid retention_days value", value)
+			return
+		}
+		retentionDays = days
+	}
+
+	result, err := ah.ArchiveService.Purge(r.Context(), retentionDays)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to purge deleted records", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, result, "Deleted records purged successfully")
+}
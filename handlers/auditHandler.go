@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/middlewares"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type AuditHandler struct {
+	AuditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{AuditService: auditService}
+}
+
+func (ha *AuditHandler) StartAuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	var request models.AuditSession
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	audit, err := ha.AuditService.StartSession(r.Context(), session.UserID, request)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to start audit", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, audit, "Audit started successfully")
+}
+
+func (ha *AuditHandler) GetAuditsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	audits, err := ha.AuditService.GetSessions(r.Context())
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get audits", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, audits, "Audits retrieved successfully")
+}
+
+func (ha *AuditHandler) GetAuditByIDHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	auditId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid audit ID", err.Error())
+		return
+	}
+
+	audit, err := ha.AuditService.GetSession(r.Context(), auditId)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusNotFound, "Failed to get audit", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, audit, "Audit retrieved successfully")
+}
+
+func (ha *AuditHandler) RecordScanHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	auditId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid audit ID", err.Error())
+		return
+	}
+
+	var request models.AuditScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	scan, err := ha.AuditService.RecordScan(r.Context(), auditId, session.UserID, request)
+	if errors.Is(err, services.ErrAuditClosed) {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to record scan", err.Error())
+		return
+	} else if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to record scan", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, scan, "Scan recorded successfully")
+}
+
+func (ha *AuditHandler) GetScansHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	auditId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid audit ID", err.Error())
+		return
+	}
+
+	scans, err := ha.AuditService.GetScans(r.Context(), auditId)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to get scans", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, scans, "Scans retrieved successfully")
+}
+
+func (ha *AuditHandler) CloseAuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	auditId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid audit ID", err.Error())
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	reconciliation, err := ha.AuditService.CloseSession(r.Context(), auditId, session.UserID)
+	if errors.Is(err, services.ErrAuditClosed) {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to close audit", err.Error())
+		return
+	} else if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to close audit", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, reconciliation, "Audit closed successfully")
+}
+
+func (ha *AuditHandler) GetReconciliationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	auditId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid audit ID", err.Error())
+		return
+	}
+
+	reconciliation, err := ha.AuditService.GetReconciliation(r.Context(), auditId)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to get reconciliation", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, reconciliation, "Reconciliation retrieved successfully")
+}
+
+func (ha *AuditHandler) MarkMissingLostHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	auditId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid audit ID", err.Error())
+		return
+	}
+
+	// The reason is optional, so an empty body is accepted
+	var request models.MarkLostRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+			return
+		}
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	result, err := ha.AuditService.MarkMissingLost(r.Context(), auditId, session.UserID, request)
+	if errors.Is(err, services.ErrAuditOpen) {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to mark missing items as lost", err.Error())
+		return
+	} else if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to mark missing items as lost", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, result, "Missing items marked as lost successfully")
+}
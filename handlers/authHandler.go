@@ -31,7 +31,7 @@ func (ah *AuthHandler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := ah.AuthService.RegisterUser(&userDTO)
+	user, err := ah.AuthService.RegisterUser(r.Context(), &userDTO)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to register user", err.Error())
 		return
@@ -51,7 +51,7 @@ func (ah *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := ah.AuthService.LoginUser(&loginRequest)
+	token, err := ah.AuthService.LoginUser(r.Context(), &loginRequest)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusUnauthorized, "Invalid credentials", err.Error())
 		return
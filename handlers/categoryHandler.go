@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -31,7 +32,7 @@ func (hc *CategoryHandler) CreateCategoryHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	category, err := hc.CategoryService.CreateCategory(categoryInput)
+	category, err := hc.CategoryService.CreateCategory(r.Context(), categoryInput)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to create category", err.Error())
 		return
@@ -59,7 +60,7 @@ func (hc *CategoryHandler) UpdateCategoryHandler(w http.ResponseWriter, r *http.
 	}
 	categoryInput.ID = categoryID
 
-	category, err := hc.CategoryService.UpdateCategory(categoryInput)
+	category, err := hc.CategoryService.UpdateCategory(r.Context(), categoryInput)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to update category", err.Error())
 		return
@@ -70,6 +71,7 @@ func (hc *CategoryHandler) UpdateCategoryHandler(w http.ResponseWriter, r *http.
 func (hc *CategoryHandler) DeleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
 	}
 
 	id := chi.URLParam(r, "id")
@@ -79,8 +81,31 @@ func (hc *CategoryHandler) DeleteCategoryHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	err = hc.CategoryService.DeleteCategory(categoryID)
-	if err != nil {
+	var options models.CategoryDeleteOptions
+	query := r.URL.Query()
+	if value := query.Get("reassign_to"); value != "" {
+		reassignTo, err := strconv.Atoi(value)
+		if err != nil || reassignTo <= 0 {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid reassign_to value", value)
+			return
+		}
+		options.ReassignTo = reassignTo
+	}
+	if value := query.Get("force"); value != "" {
+		force, err := strconv.ParseBool(value)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid force value", err.Error())
+			return
+		}
+		options.Force = force
+	}
+
+	err = hc.CategoryService.DeleteCategory(r.Context(), categoryID, options)
+	var inUseErr *models.CategoryInUseError
+	if errors.As(err, &inUseErr) {
+		JsonResp.SendError(w, http.StatusConflict, "Category still has items; pass reassign_to or force", inUseErr)
+		return
+	} else if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to delete category", err.Error())
 		return
 	}
@@ -93,7 +118,13 @@ func (hc *CategoryHandler) GetCategoriesHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	categories, err := hc.CategoryService.GetAllCategories()
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.StatusActive && status != models.StatusDeleted {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid status value", status)
+		return
+	}
+
+	categories, err := hc.CategoryService.GetAllCategories(r.Context(), status)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get categories", err.Error())
 		return
@@ -114,10 +145,45 @@ func (hc *CategoryHandler) GetCategoryByIDHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	category, err := hc.CategoryService.GetCategoryByID(categoryID)
+	category, err := hc.CategoryService.GetCategoryByID(r.Context(), categoryID)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get category", err.Error())
 		return
 	}
 	JsonResp.SendSuccess(w, category, "Category retrieved successfully")
 }
+
+func (hc *CategoryHandler) GetCategoryTreeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	tree, err := hc.CategoryService.GetCategoryTree(r.Context())
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get category tree", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, tree, "Category tree retrieved successfully")
+}
+
+func (hc *CategoryHandler) RestoreCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	categoryID, err := strconv.Atoi(id)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid category ID", err.Error())
+		return
+	}
+
+	category, err := hc.CategoryService.RestoreCategory(r.Context(), categoryID)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to restore category", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, category, "Category restored successfully")
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/middlewares"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type ItemAssignmentHandler struct {
+	ItemAssignmentService *services.ItemAssignmentService
+}
+
+func NewItemAssignmentHandler(itemAssignmentService *services.ItemAssignmentService) *ItemAssignmentHandler {
+	return &ItemAssignmentHandler{ItemAssignmentService: itemAssignmentService}
+}
+
+func (ha *ItemAssignmentHandler) CheckoutItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	var request models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	assignment, err := ha.ItemAssignmentService.Checkout(r.Context(), itemId, session.UserID, request)
+	if errors.Is(err, services.ErrItemCheckedOut) || errors.Is(err, services.ErrItemReserved) {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to check out item", err.Error())
+		return
+	} else if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to check out item", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, assignment, "Item checked out successfully")
+}
+
+func (ha *ItemAssignmentHandler) CheckinItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	// The return condition is optional, so an empty body is fine
+	var request models.CheckinRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+			return
+		}
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	assignment, err := ha.ItemAssignmentService.Checkin(r.Context(), itemId, session.UserID, request)
+	if errors.Is(err, services.ErrItemNotCheckedOut) {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to check in item", err.Error())
+		return
+	} else if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to check in item", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, assignment, "Item checked in successfully")
+}
+
+func (ha *ItemAssignmentHandler) GetMyItemsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	assignments, err := ha.ItemAssignmentService.GetAssignedItems(r.Context(), session.UserID)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get assigned items", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, assignments, "Assigned items retrieved successfully")
+}
+
+func (ha *ItemAssignmentHandler) GetOverdueItemsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	assignments, err := ha.ItemAssignmentService.GetOverdueItems(r.Context())
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get overdue items", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, assignments, "Overdue items retrieved successfully")
+}
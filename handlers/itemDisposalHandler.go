@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/middlewares"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type ItemDisposalHandler struct {
+	DisposalService *services.ItemDisposalService
+}
+
+func NewItemDisposalHandler(disposalService *services.ItemDisposalService) *ItemDisposalHandler {
+	return &ItemDisposalHandler{DisposalService: disposalService}
+}
+
+func (hd *ItemDisposalHandler) DisposeItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	var request models.DisposalRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	disposal := models.ItemDisposal{Method: request.Method, Proceeds: request.Proceeds, Notes: request.Notes}
+	if request.DisposedOn != "" {
+		const dateLayout = "2006-01-02"
+		disposal.DisposedOn, err = time.Parse(dateLayout, request.DisposedOn)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid date format. Please use YYYY-MM-DD.", err.Error())
+			return
+		}
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	created, err := hd.DisposalService.DisposeItem(r.Context(), itemId, session.UserID, disposal)
+	if errors.Is(err, services.ErrInvalidTransition) {
+		JsonResp.SendError(w, http.StatusConflict, "Item cannot be disposed of", err.Error())
+		return
+	} else if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to dispose of item", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, created, "Item disposed of successfully")
+}
+
+func (hd *ItemDisposalHandler) GetDisposalReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	// The period defaults to the year so far
+	const dateLayout = "2006-01-02"
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := now.Truncate(24 * time.Hour)
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsedFrom, err := time.Parse(dateLayout, value)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid from date. Please use YYYY-MM-DD.", err.Error())
+			return
+		}
+		from = parsedFrom
+	}
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsedTo, err := time.Parse(dateLayout, value)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid to date. Please use YYYY-MM-DD.", err.Error())
+			return
+		}
+		to = parsedTo
+	}
+
+	report, err := hd.DisposalService.GetDisposalReport(r.Context(), from, to)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to get disposals", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, report, "Disposals retrieved successfully")
+}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -10,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/middlewares"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
 	"github.com/go-chi/chi/v5"
@@ -74,6 +78,33 @@ func (hi *ItemHandler) CreateItemHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Depreciation overrides are optional and fall back to the category defaults
+	itemUsefulLife, itemSalvageValue, err := parseDepreciationOverrides(r)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid depreciation settings", err.Error())
+		return
+	}
+
+	// Replacement policy overrides are optional and fall back to the category policy
+	itemReplacementAfterDays, itemReplacementMinBookValue, err := parseReplacementOverrides(r)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid replacement settings", err.Error())
+		return
+	}
+
+	itemLocationId, err := parseLocationID(r)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid location ID", err.Error())
+		return
+	}
+
+	// Warranty and insurance details are optional
+	itemWarrantyEndsOn, err := parseWarrantyEndsOn(r)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid warranty end date. Please use YYYY-MM-DD.", err.Error())
+		return
+	}
+
 	// Handle file upload
 	file, fileHeader, err := r.FormFile("photo")
 	if err != nil {
@@ -89,8 +120,9 @@ func (hi *ItemHandler) CreateItemHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Save the uploaded file
-	filePath := filepath.Join(uploadPath, fileHeader.Filename)
+	// Save the uploaded file under a unique name, so items never share a photo
+	fileName := fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(fileHeader.Filename))
+	filePath := filepath.Join(uploadPath, fileName)
 	out, err := os.Create(filePath)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Unable to save file", err.Error())
@@ -107,17 +139,43 @@ func (hi *ItemHandler) CreateItemHandler(w http.ResponseWriter, r *http.Request)
 
 	// Initialize item data
 	itemInput := models.Item{
-		Name:            itemName,
-		CategoryID:      itemCategoryId,
-		Price:           itemPrice,
-		PurchaseDate:    formattedItemPurchaseDate,
-		PhotoURL:        filePathURL,
-		DepreciatedRate: itemDepreciatedRate,
+		Name:               itemName,
+		CategoryID:         itemCategoryId,
+		LocationID:         itemLocationId,
+		Price:              itemPrice,
+		PurchaseDate:       formattedItemPurchaseDate,
+		PhotoURL:           filePathURL,
+		DepreciatedRate:    itemDepreciatedRate,
+		DepreciationMethod: models.DepreciationMethod(r.FormValue("depreciation_method")),
+		UsefulLifeYears:    itemUsefulLife,
+		SalvageValue:       itemSalvageValue,
+
+		ReplacementAfterDays:    itemReplacementAfterDays,
+		ReplacementMinBookValue: itemReplacementMinBookValue,
+
+		WarrantyProvider:    strings.TrimSpace(r.FormValue("warranty_provider")),
+		WarrantyEndsOn:      itemWarrantyEndsOn,
+		WarrantyCoverage:    strings.TrimSpace(r.FormValue("warranty_coverage")),
+		InsurancePolicyRefs: parseInsurancePolicyRefs(r),
+		LifecycleState:      models.LifecycleState(r.FormValue("lifecycle_state")),
+
+		SerialNumber: strings.TrimSpace(r.FormValue("serial_number")),
+		Manufacturer: strings.TrimSpace(r.FormValue("manufacturer")),
+		Model:        strings.TrimSpace(r.FormValue("model")),
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
 	}
 
 	// Call service to create item
-	item, err := hi.ItemService.CreateItem(itemInput)
-	if err != nil {
+	item, err := hi.ItemService.CreateItem(r.Context(), session.UserID, itemInput)
+	if errors.Is(err, services.ErrDuplicateSerialNumber) {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to create item", err.Error())
+		return
+	} else if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to create item", err.Error())
 		return
 	}
@@ -139,7 +197,7 @@ func (hi *ItemHandler) GetItemByIDHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	item, err := hi.ItemService.GetItemsByID(itemId)
+	item, err := hi.ItemService.GetItemsByID(r.Context(), itemId)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get item", err.Error())
 		return
@@ -147,6 +205,25 @@ func (hi *ItemHandler) GetItemByIDHandler(w http.ResponseWriter, r *http.Request
 	JsonResp.SendSuccess(w, item, "Item retrieved successfully")
 }
 
+func (hi *ItemHandler) LookupItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	query := r.URL.Query()
+	lookup, err := hi.ItemService.LookupItem(r.Context(), query.Get("tag"), query.Get("serial"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to look up item", err.Error())
+		return
+	}
+	if lookup == nil {
+		JsonResp.SendError(w, http.StatusNotFound, "Item not found", nil)
+		return
+	}
+	JsonResp.SendSuccess(w, lookup, "Item retrieved successfully")
+}
+
 func (hi *ItemHandler) UpdateItemHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
 		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
@@ -203,6 +280,27 @@ func (hi *ItemHandler) UpdateItemHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Depreciation overrides are optional and fall back to the category defaults
+	itemUsefulLife, itemSalvageValue, err := parseDepreciationOverrides(r)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid depreciation settings", err.Error())
+		return
+	}
+
+	// Replacement policy overrides are optional and fall back to the category policy
+	itemReplacementAfterDays, itemReplacementMinBookValue, err := parseReplacementOverrides(r)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid replacement settings", err.Error())
+		return
+	}
+
+	// Warranty and insurance details are optional
+	itemWarrantyEndsOn, err := parseWarrantyEndsOn(r)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid warranty end date. Please use YYYY-MM-DD.", err.Error())
+		return
+	}
+
 	// Handle file upload
 	file, fileHeader, err := r.FormFile("photo")
 	if err != nil {
@@ -218,8 +316,9 @@ func (hi *ItemHandler) UpdateItemHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Save the uploaded file
-	filePath := filepath.Join(uploadPath, fileHeader.Filename)
+	// Save the uploaded file under a unique name, so items never share a photo
+	fileName := fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(fileHeader.Filename))
+	filePath := filepath.Join(uploadPath, fileName)
 	out, err := os.Create(filePath)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Unable to save file", err.Error())
@@ -236,18 +335,36 @@ func (hi *ItemHandler) UpdateItemHandler(w http.ResponseWriter, r *http.Request)
 
 	// Initialize item data
 	itemInput := models.Item{
-		ID:              itemId,
-		Name:            itemName,
-		CategoryID:      itemCategoryId,
-		Price:           itemPrice,
-		PurchaseDate:    formattedItemPurchaseDate,
-		PhotoURL:        filePathURL,
-		DepreciatedRate: itemDepreciatedRate,
+		ID:                 itemId,
+		Name:               itemName,
+		CategoryID:         itemCategoryId,
+		Price:              itemPrice,
+		PurchaseDate:       formattedItemPurchaseDate,
+		PhotoURL:           filePathURL,
+		DepreciatedRate:    itemDepreciatedRate,
+		DepreciationMethod: models.DepreciationMethod(r.FormValue("depreciation_method")),
+		UsefulLifeYears:    itemUsefulLife,
+		SalvageValue:       itemSalvageValue,
+
+		ReplacementAfterDays:    itemReplacementAfterDays,
+		ReplacementMinBookValue: itemReplacementMinBookValue,
+
+		WarrantyProvider:    strings.TrimSpace(r.FormValue("warranty_provider")),
+		WarrantyEndsOn:      itemWarrantyEndsOn,
+		WarrantyCoverage:    strings.TrimSpace(r.FormValue("warranty_coverage")),
+		InsurancePolicyRefs: parseInsurancePolicyRefs(r),
+
+		SerialNumber: strings.TrimSpace(r.FormValue("serial_number")),
+		Manufacturer: strings.TrimSpace(r.FormValue("manufacturer")),
+		Model:        strings.TrimSpace(r.FormValue("model")),
 	}
 
 	// Call service to update item
-	item, err := hi.ItemService.UpdateItem(itemInput)
-	if err != nil {
+	item, err := hi.ItemService.UpdateItem(r.Context(), itemInput)
+	if errors.Is(err, services.ErrDuplicateSerialNumber) || errors.Is(err, services.ErrDepreciationPosted) {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to update item", err.Error())
+		return
+	} else if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to update item", err.Error())
 		return
 	}
@@ -270,18 +387,12 @@ func (hi *ItemHandler) DeleteItemHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Call service to delete item
-	photoUrl, err := hi.ItemService.DeleteItem(itemId)
+	err = hi.ItemService.DeleteItem(r.Context(), itemId)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to delete item", err.Error())
 		return
 	}
 
-	err = os.Remove(photoUrl)
-	if err != nil {
-		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to remove item", err.Error())
-		return
-	}
-
 	JsonResp.SendSuccess(w, nil, "Item deleted successfully")
 }
 
@@ -290,7 +401,50 @@ func (hi *ItemHandler) GetAllItemsHandler(w http.ResponseWriter, r *http.Request
 		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
 		return
 	}
-	items, err := hi.ItemService.GetAllItems()
+
+	var filter models.ItemFilter
+	query := r.URL.Query()
+	if status := query.Get("status"); status != "" {
+		if status != models.StatusActive && status != models.StatusDeleted {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid status value", status)
+			return
+		}
+		filter.Status = status
+	}
+	if categoryID := query.Get("category_id"); categoryID != "" {
+		id, err := strconv.Atoi(categoryID)
+		if err != nil || id <= 0 {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid category ID", categoryID)
+			return
+		}
+		filter.CategoryID = id
+	}
+	if locationID := query.Get("location_id"); locationID != "" {
+		id, err := strconv.Atoi(locationID)
+		if err != nil || id <= 0 {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid location ID", locationID)
+			return
+		}
+		filter.LocationID = id
+	}
+	if state := query.Get("state"); state != "" {
+		if !models.LifecycleState(state).IsValid() {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid state value", state)
+			return
+		}
+		filter.State = models.LifecycleState(state)
+	}
+	filter.Search = strings.TrimSpace(query.Get("search"))
+	if includeDescendants := query.Get("include_descendants"); includeDescendants != "" {
+		include, err := strconv.ParseBool(includeDescendants)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid include_descendants value", err.Error())
+			return
+		}
+		filter.IncludeDescendants = include
+	}
+
+	items, err := hi.ItemService.GetAllItems(r.Context(), filter)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get items", err.Error())
 		return
@@ -305,14 +459,197 @@ func (hi *ItemHandler) GetReplacementItemsHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	items, err := hi.ItemService.GetReplacementItems()
+	page, limit, err := parsePagination(r)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid pagination", err.Error())
+		return
+	}
+
+	items, totalItems, err := hi.ItemService.GetReplacementItems(r.Context(), page, limit)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get replacement items", err.Error())
 		return
 	}
-	if len(items) == 0 {
+	if totalItems == 0 {
 		JsonResp.SendSuccess(w, nil, "No replacement items available")
-	} else {
-		JsonResp.SendSuccess(w, items, "Replacement items retrieved successfully")
+		return
+	}
+
+	totalPages := (totalItems + limit - 1) / limit
+	JsonResp.SendPaginatedResponse(w, items, page, limit, totalItems, totalPages, "Replacement items retrieved successfully")
+}
+
+func (hi *ItemHandler) TransitionItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	var request models.TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	transition, err := hi.ItemService.TransitionItem(r.Context(), itemId, session.UserID, request)
+	if errors.Is(err, services.ErrInvalidTransition) {
+		JsonResp.SendError(w, http.StatusConflict, "Transition not allowed", err.Error())
+		return
+	}
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to transition item", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, transition, "Item transitioned successfully")
+}
+
+func (hi *ItemHandler) GetItemTransitionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	transitions, err := hi.ItemService.GetTransitions(r.Context(), itemId)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get item transitions", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, transitions, "Item transitions retrieved successfully")
+}
+
+func (hi *ItemHandler) GetWarrantyExpiringHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	withinDays, err := parseWithinDays(r, models.DefaultWarrantyAlertDays)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid within value", err.Error())
+		return
+	}
+
+	expiries, err := hi.ItemService.GetWarrantyExpiring(r.Context(), withinDays)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get expiring warranties", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, expiries, "Expiring warranties retrieved successfully")
+}
+
+// parseWarrantyEndsOn reads the optional warranty_ends_on form field
+func parseWarrantyEndsOn(r *http.Request) (*time.Time, error) {
+	value := r.FormValue("warranty_ends_on")
+	if value == "" {
+		return nil, nil
+	}
+	endsOn, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &endsOn, nil
+}
+
+// parseInsurancePolicyRefs reads the insurance_policy_refs form field, which
+// may be repeated, dropping blank values. It returns nil when none are sent.
+func parseInsurancePolicyRefs(r *http.Request) []string {
+	var refs []string
+	for _, value := range r.MultipartForm.Value["insurance_policy_refs"] {
+		if ref := strings.TrimSpace(value); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// parseDepreciationOverrides reads the optional useful_life_years and
+// salvage_value form fields, returning zero for fields that are not set.
+func parseDepreciationOverrides(r *http.Request) (int, float64, error) {
+	var usefulLife int
+	var salvageValue float64
+	var err error
+
+	if value := r.FormValue("useful_life_years"); value != "" {
+		if usefulLife, err = strconv.Atoi(value); err != nil {
+			return 0, 0, err
+		}
+	}
+	if value := r.FormValue("salvage_value"); value != "" {
+		if salvageValue, err = strconv.ParseFloat(value, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	return usefulLife, salvageValue, nil
+}
+
+// parseLocationID reads the optional location_id form field, returning zero when it is not set.
+func parseLocationID(r *http.Request) (int, error) {
+	value := r.FormValue("location_id")
+	if value == "" {
+		return 0, nil
+	}
+	locationID, err := strconv.Atoi(value)
+	if err != nil || locationID <= 0 {
+		return 0, errors.New("invalid location id")
+	}
+	return locationID, nil
+}
+
+// parseReplacementOverrides reads the optional replacement_after_days and
+// replacement_min_book_value form fields, returning zero for fields that are not set.
+func parseReplacementOverrides(r *http.Request) (int, float64, error) {
+	var afterDays int
+	var minBookValue float64
+	var err error
+
+	if value := r.FormValue("replacement_after_days"); value != "" {
+		if afterDays, err = strconv.Atoi(value); err != nil {
+			return 0, 0, err
+		}
+	}
+	if value := r.FormValue("replacement_min_book_value"); value != "" {
+		if minBookValue, err = strconv.ParseFloat(value, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	return afterDays, minBookValue, nil
+}
+
+func (hi *ItemHandler) RestoreItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	itemId, err := strconv.Atoi(id)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	item, err := hi.ItemService.RestoreItem(r.Context(), itemId)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to restore item", err.Error())
+		return
 	}
+	JsonResp.SendSuccess(w, item, "Item restored successfully")
 }
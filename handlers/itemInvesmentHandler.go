@@ -3,7 +3,9 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
 	"github.com/go-chi/chi/v5"
 )
@@ -22,7 +24,7 @@ func (inh *ItemInvestmentHandler) CountAllItemInvestmentsHandler(w http.Response
 		return
 	}
 
-	itemInvesment, err := inh.ItemInvestmentService.CountAllItemInvestments()
+	itemInvesment, err := inh.ItemInvestmentService.CountAllItemInvestments(r.Context())
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Internal Server Error", err)
 		return
@@ -43,10 +45,73 @@ func (inh *ItemInvestmentHandler) GetItemInvesmentByItemIdHandler(w http.Respons
 		return
 	}
 
-	itemInvesment, err := inh.ItemInvestmentService.GetByItemID(itemId)
+	// Optional point-in-time valuation
+	var asOf *time.Time
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		const dateLayout = "2006-01-02"
+		parsedAsOf, err := time.Parse(dateLayout, value)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid as_of date. Please use YYYY-MM-DD.", err.Error())
+			return
+		}
+		asOf = &parsedAsOf
+	}
+
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = models.ScheduleYearly
+	} else if period != models.ScheduleMonthly && period != models.ScheduleYearly {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid period. Please use month or year.", period)
+		return
+	}
+
+	itemInvesment, err := inh.ItemInvestmentService.GetByItemID(r.Context(), itemId, asOf, period)
 	if err != nil {
 		JsonResp.SendError(w, http.StatusInternalServerError, "Internal Server Error", err)
 		return
 	}
 	JsonResp.SendSuccess(w, itemInvesment, "")
 }
+
+func (inh *ItemInvestmentHandler) GetInvestmentSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = models.GroupByCategory
+	} else if groupBy != models.GroupByCategory && groupBy != models.GroupByPurchaseYear && groupBy != models.GroupByAgeBucket {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid group_by. Please use category, purchase_year or age_bucket.", groupBy)
+		return
+	}
+
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		const dateLayout = "2006-01-02"
+		parsedAsOf, err := time.Parse(dateLayout, value)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid as_of date. Please use YYYY-MM-DD.", err.Error())
+			return
+		}
+		asOf = parsedAsOf
+	}
+
+	includeDeleted := false
+	if value := r.URL.Query().Get("include_deleted"); value != "" {
+		parsedIncludeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid include_deleted value", err.Error())
+			return
+		}
+		includeDeleted = parsedIncludeDeleted
+	}
+
+	summary, err := inh.ItemInvestmentService.SummarizeInvestments(r.Context(), groupBy, asOf, includeDeleted)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, summary, "")
+}
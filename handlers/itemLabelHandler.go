@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+func (hi *ItemHandler) GetItemLabelPNGHandler(w http.ResponseWriter, r *http.Request) {
+	hi.sendItemLabel(w, r, "image/png", utils.WriteLabelPNG)
+}
+
+func (hi *ItemHandler) GetItemLabelSVGHandler(w http.ResponseWriter, r *http.Request) {
+	hi.sendItemLabel(w, r, "image/svg+xml", utils.WriteLabelSVG)
+}
+
+// sendItemLabel renders the barcode label of the item in the URL. The label
+// is rendered in full before anything is sent so a failure can still be
+// reported as JSON.
+func (hi *ItemHandler) sendItemLabel(w http.ResponseWriter, r *http.Request, contentType string, writeLabel func(io.Writer, string) error) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	item, err := hi.ItemService.GetItemsByID(r.Context(), itemId)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get item", err.Error())
+		return
+	}
+	if item == nil {
+		JsonResp.SendError(w, http.StatusNotFound, "Item not found", itemId)
+		return
+	}
+
+	var label bytes.Buffer
+	if err := writeLabel(&label, item.AssetTag); err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to render label", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(label.Bytes())
+}
+
+func (hi *ItemHandler) GetLabelSheetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	ids, err := parseIDList(r, "ids")
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid ids value", err.Error())
+		return
+	}
+
+	items, err := hi.ItemService.GetLabelItems(r.Context(), ids)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to get items", err.Error())
+		return
+	}
+
+	var sheet bytes.Buffer
+	if err := utils.WriteLabelSheetPDF(&sheet, items); err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to render labels", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "labels.pdf"))
+	w.Write(sheet.Bytes())
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/middlewares"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type ItemMovementHandler struct {
+	ItemMovementService *services.ItemMovementService
+}
+
+func NewItemMovementHandler(itemMovementService *services.ItemMovementService) *ItemMovementHandler {
+	return &ItemMovementHandler{ItemMovementService: itemMovementService}
+}
+
+func (hm *ItemMovementHandler) MoveItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	var request models.MoveItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	movement, err := hm.ItemMovementService.MoveItem(r.Context(), itemId, session.UserID, request)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to move item", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, movement, "Item moved successfully")
+}
+
+func (hm *ItemMovementHandler) GetItemMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	movements, err := hm.ItemMovementService.GetMovements(r.Context(), itemId)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get item movements", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, movements, "Item movements retrieved successfully")
+}
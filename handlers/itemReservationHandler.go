@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/middlewares"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type ItemReservationHandler struct {
+	ItemReservationService *services.ItemReservationService
+}
+
+func NewItemReservationHandler(itemReservationService *services.ItemReservationService) *ItemReservationHandler {
+	return &ItemReservationHandler{ItemReservationService: itemReservationService}
+}
+
+func (hr *ItemReservationHandler) BookReservationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	var request models.ReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	reservation, err := hr.ItemReservationService.Book(r.Context(), itemId, session.UserID, request)
+	if errors.Is(err, services.ErrReservationConflict) {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to book item", err.Error())
+		return
+	} else if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to book item", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, reservation, "Item reserved successfully")
+}
+
+func (hr *ItemReservationHandler) CancelReservationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	reservationId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid reservation ID", err.Error())
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	reservation, err := hr.ItemReservationService.Cancel(r.Context(), reservationId, *session)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to cancel reservation", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, reservation, "Reservation cancelled successfully")
+}
+
+func (hr *ItemReservationHandler) GetAvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid from value", err.Error())
+		return
+	}
+	to, err := parseTimeParam(r, "to")
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid to value", err.Error())
+		return
+	}
+	if !to.After(from) {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid period", "to must be after from")
+		return
+	}
+
+	availability, err := hr.ItemReservationService.GetAvailability(r.Context(), itemId, from, to)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get availability", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, availability, "Availability retrieved successfully")
+}
+
+func (hr *ItemReservationHandler) GetMyReservationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	reservations, err := hr.ItemReservationService.GetUpcomingReservations(r.Context(), session.UserID)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get reservations", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, reservations, "Reservations retrieved successfully")
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type LocationHandler struct {
+	LocationService *services.LocationService
+}
+
+func NewLocationHandler(locationService *services.LocationService) *LocationHandler {
+	return &LocationHandler{LocationService: locationService}
+}
+
+func (hl *LocationHandler) CreateLocationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	var locationInput models.Location
+	if err := json.NewDecoder(r.Body).Decode(&locationInput); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	location, err := hl.LocationService.CreateLocation(r.Context(), locationInput)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to create location", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, location, "Location created successfully")
+}
+
+func (hl *LocationHandler) UpdateLocationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	locationID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid location ID", err.Error())
+		return
+	}
+
+	var locationInput models.Location
+	if err := json.NewDecoder(r.Body).Decode(&locationInput); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+	locationInput.ID = locationID
+
+	location, err := hl.LocationService.UpdateLocation(r.Context(), locationInput)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to update location", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, location, "Location updated successfully")
+}
+
+func (hl *LocationHandler) DeleteLocationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	locationID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid location ID", err.Error())
+		return
+	}
+
+	if err := hl.LocationService.DeleteLocation(r.Context(), locationID); err != nil {
+		JsonResp.SendError(w, http.StatusConflict, "Failed to delete location", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, nil, "Location deleted successfully")
+}
+
+func (hl *LocationHandler) GetLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	locations, err := hl.LocationService.GetAllLocations(r.Context())
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get locations", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, locations, "Locations retrieved successfully")
+}
+
+func (hl *LocationHandler) GetLocationByIDHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	locationID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid location ID", err.Error())
+		return
+	}
+
+	location, err := hl.LocationService.GetLocationByID(r.Context(), locationID)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusNotFound, "Failed to get location", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, location, "Location retrieved successfully")
+}
+
+func (hl *LocationHandler) GetLocationSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	locationID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid location ID", err.Error())
+		return
+	}
+
+	summary, err := hl.LocationService.GetLocationSummary(r.Context(), locationID)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get location summary", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, summary, "Location summary retrieved successfully")
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/middlewares"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+	"github.com/go-chi/chi/v5"
+)
+
+type MaintenanceHandler struct {
+	MaintenanceService *services.MaintenanceService
+}
+
+func NewMaintenanceHandler(maintenanceService *services.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{MaintenanceService: maintenanceService}
+}
+
+func (hm *MaintenanceHandler) CreateMaintenanceRecordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	// Parse form with max memory limit for file uploads
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Unable to parse form", err.Error())
+		return
+	}
+
+	record := models.MaintenanceRecord{
+		Type:        models.MaintenanceType(r.FormValue("maintenance_type")),
+		Vendor:      r.FormValue("vendor"),
+		Description: r.FormValue("description"),
+	}
+
+	// Cost is optional, e.g. for in-house servicing
+	if cost := r.FormValue("cost"); cost != "" {
+		record.Cost, err = strconv.ParseFloat(cost, 64)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid cost", err.Error())
+			return
+		}
+	}
+
+	// A record can complete one of the item's maintenance plans
+	if planId := r.FormValue("plan_id"); planId != "" {
+		record.PlanID, err = strconv.Atoi(planId)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid plan ID", err.Error())
+			return
+		}
+	}
+
+	if isWarrantyClaim := r.FormValue("is_warranty_claim"); isWarrantyClaim != "" {
+		record.IsWarrantyClaim, err = strconv.ParseBool(isWarrantyClaim)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid is_warranty_claim value", err.Error())
+			return
+		}
+	}
+
+	if performedOn := r.FormValue("performed_on"); performedOn != "" {
+		const dateLayout = "2006-01-02"
+		record.PerformedOn, err = time.Parse(dateLayout, performedOn)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid date format. Please use YYYY-MM-DD.", err.Error())
+			return
+		}
+	}
+
+	session := middlewares.SessionFromContext(r.Context())
+	if session == nil {
+		JsonResp.SendError(w, http.StatusUnauthorized, "Unauthorized", nil)
+		return
+	}
+
+	// Attachments are optional; each upload is saved under a unique name
+	uploadPath := "./uploads/maintenance"
+	for _, fileHeader := range r.MultipartForm.File["attachments"] {
+		if err := os.MkdirAll(uploadPath, os.ModePerm); err != nil {
+			removeUploads(record.AttachmentURLs)
+			JsonResp.SendError(w, http.StatusInternalServerError, "Failed to create upload directory", err.Error())
+			return
+		}
+		fileName := fmt.Sprintf("%d-%d-%s", itemId, time.Now().UnixNano(), filepath.Base(fileHeader.Filename))
+		filePath, err := saveUpload(fileHeader, filepath.Join(uploadPath, fileName))
+		if err != nil {
+			removeUploads(record.AttachmentURLs)
+			JsonResp.SendError(w, http.StatusInternalServerError, "Unable to save file", err.Error())
+			return
+		}
+		record.AttachmentURLs = append(record.AttachmentURLs, filePath)
+	}
+
+	created, err := hm.MaintenanceService.CreateRecord(r.Context(), itemId, session.UserID, record)
+	if err != nil {
+		removeUploads(record.AttachmentURLs)
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to record maintenance", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, created, "Maintenance recorded successfully")
+}
+
+func (hm *MaintenanceHandler) GetMaintenanceRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	itemId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid item ID", err.Error())
+		return
+	}
+
+	records, err := hm.MaintenanceService.GetRecords(r.Context(), itemId)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get maintenance records", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, records, "Maintenance records retrieved successfully")
+}
+
+func (hm *MaintenanceHandler) CreateMaintenancePlanHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	var planInput models.MaintenancePlan
+	if err := json.NewDecoder(r.Body).Decode(&planInput); err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return
+	}
+
+	plan, err := hm.MaintenanceService.CreatePlan(r.Context(), planInput)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Failed to create maintenance plan", err.Error())
+		return
+	}
+	JsonResp.SendCreated(w, plan, "Maintenance plan created successfully")
+}
+
+func (hm *MaintenanceHandler) GetMaintenancePlansHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	plans, err := hm.MaintenanceService.GetPlans(r.Context())
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get maintenance plans", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, plans, "Maintenance plans retrieved successfully")
+}
+
+func (hm *MaintenanceHandler) DeleteMaintenancePlanHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	planId, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid plan ID", err.Error())
+		return
+	}
+
+	if err := hm.MaintenanceService.DeletePlan(r.Context(), planId); err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to delete maintenance plan", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, nil, "Maintenance plan deleted successfully")
+}
+
+func (hm *MaintenanceHandler) GetMaintenanceDueHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	withinDays, err := parseWithinDays(r, 30)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid within value", err.Error())
+		return
+	}
+
+	dueList, err := hm.MaintenanceService.GetDueList(r.Context(), withinDays)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to get due maintenance", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, dueList, "Due maintenance retrieved successfully")
+}
+
+// saveUpload copies an uploaded file to filePath and returns the path with
+// forward slashes
+func saveUpload(fileHeader *multipart.FileHeader, filePath string) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	out, err := os.Create(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, file); err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+	return strings.ReplaceAll(filePath, "\\", "/"), nil
+}
+
+// removeUploads cleans up saved files when the request fails
+func removeUploads(filePaths []string) {
+	for _, filePath := range filePaths {
+		os.Remove(filePath)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPage  = 1
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+// parsePagination reads the optional page and limit query parameters
+func parsePagination(r *http.Request) (int, int, error) {
+	page, limit := defaultPage, defaultLimit
+
+	if value := r.URL.Query().Get("page"); value != "" {
+		parsedPage, err := strconv.Atoi(value)
+		if err != nil || parsedPage < 1 {
+			return 0, 0, errors.New("page must be a positive number")
+		}
+		page = parsedPage
+	}
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsedLimit, err := strconv.Atoi(value)
+		if err != nil || parsedLimit < 1 || parsedLimit > maxLimit {
+			return 0, 0, errors.New("limit must be between 1 and 100")
+		}
+		limit = parsedLimit
+	}
+	return page, limit, nil
+}
+
+// parseTimeParam reads a query parameter holding either an RFC 3339
+// timestamp or a YYYY-MM-DD date, which is taken as midnight UTC.
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, errors.New(name + " is required")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New(name + " must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	return t, nil
+}
+
+// maxWithinDays caps the look-ahead of the within query parameter
+const maxWithinDays = 3650
+
+// parseWithinDays reads the optional within query parameter, a number of
+// days such as "30" or "30d", or of weeks such as "4w"
+func parseWithinDays(r *http.Request, defaultDays int) (int, error) {
+	value := strings.TrimSpace(r.URL.Query().Get("within"))
+	if value == "" {
+		return defaultDays, nil
+	}
+
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(value, "d"):
+		value = strings.TrimSuffix(value, "d")
+	case strings.HasSuffix(value, "w"):
+		value = strings.TrimSuffix(value, "w")
+		multiplier = 7
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 || days*multiplier > maxWithinDays {
+		return 0, errors.New("within must be a number of days such as 30d, or weeks such as 4w, up to 3650 days")
+	}
+	return days * multiplier, nil
+}
+
+// parseIDList reads a comma-separated list of ids such as "1,2,3" from the
+// named query parameter
+func parseIDList(r *http.Request, name string) ([]int, error) {
+	var ids []int
+	for _, value := range strings.Split(r.URL.Query().Get(name), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New(name + " must be a comma-separated list of ids")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/utils"
+)
+
+type ReportHandler struct {
+	ReportService *services.ReportService
+}
+
+func NewReportHandler(service *services.ReportService) *ReportHandler {
+	return &ReportHandler{ReportService: service}
+}
+
+func (hr *ReportHandler) GetAssetRegisterHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	} else if format != "csv" && format != "xlsx" {
+		JsonResp.SendError(w, http.StatusBadRequest, "Invalid format. Please use csv or xlsx.", format)
+		return
+	}
+
+	const dateLayout = "2006-01-02"
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		parsedAsOf, err := time.Parse(dateLayout, value)
+		if err != nil {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid as_of date. Please use YYYY-MM-DD.", err.Error())
+			return
+		}
+		asOf = parsedAsOf
+	}
+
+	register, err := hr.ReportService.GetAssetRegister(r.Context(), asOf)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to build asset register", err.Error())
+		return
+	}
+
+	rows := assetRegisterRows(register)
+	fileName := fmt.Sprintf("asset-register-%s.%s", asOf.Format(dateLayout), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		if err := utils.WriteXLSX(w, "Asset Register", rows); err != nil {
+			JsonResp.SendError(w, http.StatusInternalServerError, "Failed to write asset register", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	csvWriter := csv.NewWriter(w)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			switch value := cell.(type) {
+			case nil:
+			case float64:
+				record[i] = fmt.Sprintf("%.2f", value)
+			default:
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		if err := csvWriter.Write(record); err != nil {
+			JsonResp.SendError(w, http.StatusInternalServerError, "Failed to write asset register", err.Error())
+			return
+		}
+	}
+	csvWriter.Flush()
+}
+
+// assetRegisterRows lays the register out as a table: a header, the item rows
+// of each category followed by its subtotal, and a grand total at the end.
+func assetRegisterRows(register *models.AssetRegister) [][]interface{} {
+	const dateLayout = "2006-01-02"
+	rows := [][]interface{}{{
+		"Item ID", "Item Name", "Category", "Purchase Date", "Cost", "Depreciation Method",
+		"Useful Life (Years)", "Accumulated Depreciation", "Net Book Value", "Status",
+	}}
+
+	for _, category := range register.Categories {
+		for _, row := range category.Rows {
+			rows = append(rows, []interface{}{
+				row.ItemID, row.ItemName, row.CategoryName, row.PurchaseDate.Format(dateLayout), row.Cost, string(row.DepreciationMethod),
+				row.UsefulLifeYears, row.AccumulatedDepreciation, row.NetBookValue, row.Status,
+			})
+		}
+		rows = append(rows, assetRegisterTotalsRow("Subtotal "+category.CategoryName, category.Subtotal))
+	}
+	return append(rows, assetRegisterTotalsRow("Grand Total", register.GrandTotal))
+}
+
+func assetRegisterTotalsRow(label string, totals models.AssetRegisterTotals) []interface{} {
+	return []interface{}{
+		nil, label, nil, nil, totals.Cost, nil,
+		nil, totals.AccumulatedDepreciation, totals.NetBookValue, fmt.Sprintf("%d items", totals.ItemCount),
+	}
+}
+
+func (hr *ReportHandler) GetReplacementForecastHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		JsonResp.SendError(w, http.StatusMethodNotAllowed, "Method not allowed", r.Method)
+		return
+	}
+
+	months := 12
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsedMonths, err := strconv.Atoi(value)
+		if err != nil || parsedMonths < 1 || parsedMonths > 120 {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid months. Please use a number between 1 and 120.", value)
+			return
+		}
+		months = parsedMonths
+	}
+
+	// Yearly inflation in percent applied to the original price
+	inflationRate := 0.0
+	if value := r.URL.Query().Get("inflation_rate"); value != "" {
+		parsedInflationRate, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(parsedInflationRate) || math.IsInf(parsedInflationRate, 0) || parsedInflationRate <= -100 {
+			JsonResp.SendError(w, http.StatusBadRequest, "Invalid inflation rate. Please use a number greater than -100.", value)
+			return
+		}
+		inflationRate = parsedInflationRate
+	}
+
+	forecast, err := hr.ReportService.GetReplacementForecast(r.Context(), months, inflationRate)
+	if err != nil {
+		JsonResp.SendError(w, http.StatusInternalServerError, "Failed to build replacement forecast", err.Error())
+		return
+	}
+	JsonResp.SendSuccess(w, forecast, "Replacement forecast retrieved successfully")
+}
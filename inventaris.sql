@@ -1,13 +1,77 @@
+-- Needed for the reservation overlap constraint on (item_id, period)
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
 CREATE TYPE status_enum AS ENUM (
 	'active',
 	'deleted'
 )
 
+CREATE TYPE depreciation_method_enum AS ENUM (
+	'straight_line',
+	'declining_balance',
+	'double_declining',
+	'sum_of_years_digits'
+);
+
+CREATE TYPE location_type_enum AS ENUM (
+	'site',
+	'building',
+	'floor',
+	'room'
+);
+
+CREATE TYPE reservation_status_enum AS ENUM (
+	'booked',
+	'cancelled',
+	'checked_out', -- handed off to an item assignment when it began
+	'missed'       -- ended while the item was still out with someone else
+);
+
+CREATE TYPE user_role_enum AS ENUM (
+	'user',
+	'admin'
+);
+
+-- Where an item is in its life. Separate from status_enum, which only tracks soft deletes.
+CREATE TYPE lifecycle_state_enum AS ENUM (
+	'requested',
+	'ordered',
+	'in_stock',
+	'in_use',
+	'under_repair',
+	'lost',
+	'retired',
+	'disposed'
+);
+
+CREATE TYPE disposal_method_enum AS ENUM (
+	'sold',
+	'scrapped',
+	'donated'
+);
+
+CREATE TYPE audit_status_enum AS ENUM (
+	'open',
+	'closed'
+);
+
+CREATE TYPE audit_finding_enum AS ENUM (
+	'missing',
+	'unexpected',
+	'mislocated'
+);
+
+CREATE TYPE maintenance_type_enum AS ENUM (
+	'preventive',
+	'corrective'
+);
+
 CREATE TABLE users (
     id SERIAL PRIMARY KEY,
     username VARCHAR(50) UNIQUE NOT NULL,
     password_hash TEXT NOT NULL, -- Store hashed password
 	email VARCHAR UNIQUE NOT NULL,
+	role user_role_enum NOT NULL DEFAULT 'user',
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
@@ -26,40 +90,306 @@ CREATE TABLE categories (
     id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     description TEXT,
+	parent_id INTEGER REFERENCES categories(id) CHECK (parent_id <> id), -- NULL for a top-level category
 	status status_enum DEFAULT 'active',
+	depreciation_method depreciation_method_enum NOT NULL DEFAULT 'straight_line',
+	useful_life_years INTEGER NOT NULL DEFAULT 5 CHECK (useful_life_years > 0),
+	salvage_value DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (salvage_value >= 0),
+	replacement_after_days INTEGER NOT NULL DEFAULT 100 CHECK (replacement_after_days > 0),
+	replacement_min_book_value DECIMAL(10, 2) CHECK (replacement_min_book_value >= 0),
+	tag_prefix VARCHAR(10) CHECK (tag_prefix ~ '^[A-Z0-9]{2,10}$'), -- NULL inherits the parent's prefix
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	deleted_at TIMESTAMP -- set when status becomes 'deleted', drives the purge retention
 );
 
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+
+-- Locations Table, a site > building > floor > room hierarchy
+CREATE TABLE locations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type location_type_enum NOT NULL,
+    parent_id INTEGER REFERENCES locations(id) CHECK (parent_id <> id), -- NULL for a site
+    description TEXT,
+	status status_enum DEFAULT 'active',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_locations_parent_id ON locations (parent_id);
+
 -- Items Table
 CREATE TABLE items (
     id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
+    asset_tag VARCHAR(32) NOT NULL UNIQUE, -- such as IT-2026-000123, never changes
+    serial_number VARCHAR(100) CONSTRAINT items_serial_number_key UNIQUE,
+    manufacturer VARCHAR(100),
+    model VARCHAR(100),
     category_id INTEGER REFERENCES categories(id),
+    location_id INTEGER REFERENCES locations(id),
     photo_url VARCHAR(255),
     price DECIMAL(10, 2),
     purchase_date DATE,
-    total_usage_days INTEGER DEFAULT 0,
     is_replacement_needed BOOLEAN DEFAULT FALSE,
 	status status_enum DEFAULT 'active',
-	depreciated_rate INTEGER,
+	depreciated_rate INTEGER CHECK (depreciated_rate BETWEEN 0 AND 100),
+	depreciation_method depreciation_method_enum, -- NULL falls back to the category
+	useful_life_years INTEGER CHECK (useful_life_years > 0),
+	salvage_value DECIMAL(10, 2) CHECK (salvage_value >= 0),
+	replacement_after_days INTEGER CHECK (replacement_after_days > 0), -- NULL falls back to the category
+	replacement_min_book_value DECIMAL(10, 2) CHECK (replacement_min_book_value >= 0),
+	lifecycle_state lifecycle_state_enum NOT NULL DEFAULT 'in_stock', -- changed through transitions only
+	warranty_provider VARCHAR(100),
+	warranty_ends_on DATE,
+	warranty_coverage TEXT,
+	warranty_alerted_for DATE, -- the warranty_ends_on an expiry alert was sent for
+	insurance_policy_refs TEXT[] NOT NULL DEFAULT '{}',
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_items_location_id ON items (location_id);
+CREATE INDEX idx_items_warranty_ends_on ON items (warranty_ends_on) WHERE status = 'active';
+
+-- Last asset tag number handed out per prefix and year
+CREATE TABLE asset_tag_sequences (
+    prefix VARCHAR(10) NOT NULL,
+    year INTEGER NOT NULL,
+    last_value INTEGER NOT NULL,
+    PRIMARY KEY (prefix, year)
 );
 
 -- Investment Tracking Table
 CREATE TABLE item_investments (
     id SERIAL PRIMARY KEY,
-    item_id INTEGER REFERENCES items(id),
+    item_id INTEGER REFERENCES items(id) ON DELETE CASCADE,
     initial_price DECIMAL(10, 2),
     current_value DECIMAL(10, 2),
     last_depreciation_date DATE
 );
 
+-- Depreciation ledger, one row per item and posted month
+CREATE TABLE depreciation_entries (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    opening_value DECIMAL(10, 2) NOT NULL,
+    depreciation_amount DECIMAL(10, 2) NOT NULL,
+    closing_value DECIMAL(10, 2) NOT NULL,
+    posted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (item_id, period_start)
+);
+
+-- Item movement history. Rows are never changed once written.
+CREATE TABLE item_movements (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    from_location_id INTEGER REFERENCES locations(id), -- NULL when the item had no location yet
+    to_location_id INTEGER NOT NULL REFERENCES locations(id),
+    moved_by INTEGER REFERENCES users(id),
+    reason TEXT NOT NULL,
+    moved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_item_movements_item_id ON item_movements (item_id, moved_at);
+
+CREATE FUNCTION prevent_item_movement_update() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'item movements cannot be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER item_movements_immutable BEFORE UPDATE ON item_movements
+    FOR EACH ROW EXECUTE FUNCTION prevent_item_movement_update();
+
+-- Lifecycle state changes of items, starting with the state each item was
+-- created in. Rows are never updated.
+CREATE TABLE item_transitions (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    from_state lifecycle_state_enum, -- NULL for the state the item was created in
+    to_state lifecycle_state_enum NOT NULL,
+    reason TEXT NOT NULL,
+    transitioned_by INTEGER REFERENCES users(id),
+    transitioned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_item_transitions_item_id ON item_transitions (item_id, transitioned_at);
+
+CREATE FUNCTION prevent_item_transition_update() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'item transitions cannot be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER item_transitions_immutable BEFORE UPDATE ON item_transitions
+    FOR EACH ROW EXECUTE FUNCTION prevent_item_transition_update();
+
+-- Items sold, scrapped or donated. The item's name and category are copied so
+-- the record survives a purge of the item.
+CREATE TABLE item_disposals (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER UNIQUE REFERENCES items(id) ON DELETE SET NULL,
+    item_name VARCHAR(255) NOT NULL,
+    category_name VARCHAR(255) NOT NULL,
+    disposed_on DATE NOT NULL,
+    method disposal_method_enum NOT NULL,
+    proceeds DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (proceeds >= 0),
+    book_value DECIMAL(10, 2) NOT NULL, -- item_investments.current_value when disposed of
+    gain_loss DECIMAL(10, 2) NOT NULL,
+    notes TEXT,
+    approved_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_item_disposals_disposed_on ON item_disposals (disposed_on);
+
+-- Items lent to users. An assignment stays open until the item is checked in.
+CREATE TABLE item_assignments (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    assignee_id INTEGER NOT NULL REFERENCES users(id),
+    checked_out_by INTEGER REFERENCES users(id),
+    checked_out_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    due_date DATE,
+    checkout_condition TEXT,
+    checked_in_by INTEGER REFERENCES users(id),
+    checked_in_at TIMESTAMP,
+    return_condition TEXT
+);
+
+-- At most one open assignment per item
+CREATE UNIQUE INDEX idx_item_assignments_open ON item_assignments (item_id) WHERE checked_in_at IS NULL;
+CREATE INDEX idx_item_assignments_assignee ON item_assignments (assignee_id) WHERE checked_in_at IS NULL;
+
+-- Bookings of shared items. Active bookings of an item may not overlap.
+CREATE TABLE item_reservations (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    purpose TEXT,
+    status reservation_status_enum NOT NULL DEFAULT 'booked',
+    assignment_id INTEGER REFERENCES item_assignments(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    CHECK (ends_at > starts_at),
+    EXCLUDE USING gist (item_id WITH =, tstzrange(starts_at, ends_at) WITH &&) WHERE (status IN ('booked', 'checked_out'))
+);
+
+CREATE INDEX idx_item_reservations_user ON item_reservations (user_id, starts_at);
+
+-- Recurring maintenance, for a single item or for every item of a category and its subcategories
+CREATE TABLE maintenance_plans (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    item_id INTEGER REFERENCES items(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    interval_days INTEGER NOT NULL CHECK (interval_days > 0),
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(item_id, category_id) = 1)
+);
+
+-- Service and repair history. The costs count towards an item's total cost of ownership.
+CREATE TABLE maintenance_records (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    maintenance_type maintenance_type_enum NOT NULL,
+    performed_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    vendor VARCHAR(100),
+    cost DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (cost >= 0),
+    description TEXT NOT NULL,
+    attachment_urls TEXT[] NOT NULL DEFAULT '{}', -- uploaded files, removed when the item is purged
+    recorded_by INTEGER REFERENCES users(id),
+    plan_id INTEGER REFERENCES maintenance_plans(id) ON DELETE SET NULL, -- the plan this record completes, if any
+    is_warranty_claim BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_maintenance_records_item_id ON maintenance_records (item_id, performed_on);
+
+-- When each plan is next due for each item it covers. Kept up to date by the maintenance job.
+CREATE TABLE maintenance_schedules (
+    plan_id INTEGER NOT NULL REFERENCES maintenance_plans(id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    last_done_on DATE,
+    next_due_on DATE NOT NULL,
+    PRIMARY KEY (plan_id, item_id)
+);
+
+CREATE INDEX idx_maintenance_schedules_next_due_on ON maintenance_schedules (next_due_on);
+
+-- Physical stock-takes of a location or a category, each including their subdivisions
+CREATE TABLE audit_sessions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    location_id INTEGER REFERENCES locations(id),
+    category_id INTEGER REFERENCES categories(id),
+    status audit_status_enum NOT NULL DEFAULT 'open',
+    notes TEXT,
+    started_by INTEGER REFERENCES users(id),
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_by INTEGER REFERENCES users(id),
+    closed_at TIMESTAMP,
+    expected_count INTEGER, -- set when the session is closed
+    seen_count INTEGER,
+    CHECK (num_nonnulls(location_id, category_id) = 1)
+);
+
+-- Items seen during an audit. item_id is NULL when the scanned code matched no item.
+CREATE TABLE audit_scans (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES audit_sessions(id) ON DELETE CASCADE,
+    item_id INTEGER REFERENCES items(id) ON DELETE SET NULL,
+    scanned_code VARCHAR(100) NOT NULL,
+    location_id INTEGER REFERENCES locations(id), -- where the item was found
+    condition TEXT,
+    scanned_by INTEGER REFERENCES users(id),
+    scanned_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_scans_session_id ON audit_scans (session_id, item_id);
+
+-- The reconciliation of an audit, stored when it is closed
+CREATE TABLE audit_findings (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES audit_sessions(id) ON DELETE CASCADE,
+    kind audit_finding_enum NOT NULL,
+    item_id INTEGER REFERENCES items(id) ON DELETE SET NULL,
+    item_name VARCHAR(255),
+    code VARCHAR(100) NOT NULL, -- the asset tag of a missing item, otherwise the scanned code
+    expected_location_id INTEGER REFERENCES locations(id),
+    found_location_id INTEGER REFERENCES locations(id),
+    condition TEXT
+);
+
+CREATE INDEX idx_audit_findings_session_id ON audit_findings (session_id, kind);
+
 SELECT * FROM users
 SELECT * FROM sessions
 SELECT * FROM categories
+SELECT * FROM locations
 SELECT * FROM items
+SELECT * FROM asset_tag_sequences
 SELECT * FROM item_investments
+SELECT * FROM depreciation_entries
+SELECT * FROM item_movements
+SELECT * FROM item_transitions
+SELECT * FROM item_disposals
+SELECT * FROM item_assignments
+SELECT * FROM item_reservations
+SELECT * FROM maintenance_records
+SELECT * FROM maintenance_plans
+SELECT * FROM maintenance_schedules
+SELECT * FROM audit_sessions
+SELECT * FROM audit_scans
+SELECT * FROM audit_findings
 
 SELECT SUM(initial_price) AS total_investment, SUM(current_value) AS depreciated_value FROM item_investments;
\ No newline at end of file
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+)
+
+// DepreciationJob posts the monthly depreciation of every active item
+type DepreciationJob struct {
+	DepreciationService *services.DepreciationService
+}
+
+func NewDepreciationJob(service *services.DepreciationService) *DepreciationJob {
+	return &DepreciationJob{DepreciationService: service}
+}
+
+// Run posts the last completed month. Posting is idempotent per period, so
+// the job can run daily and only the first run after a month closes posts.
+func (j *DepreciationJob) Run(ctx context.Context) error {
+	// Step back from the first of the month, as AddDate normalizes Oct 31
+	// minus a month to Oct 1
+	thisMonth, _ := services.MonthBounds(time.Now())
+	return j.PostPeriod(ctx, thisMonth.AddDate(0, -1, 0))
+}
+
+// PostPeriod posts the month containing period
+func (j *DepreciationJob) PostPeriod(ctx context.Context, period time.Time) error {
+	run, err := j.DepreciationService.PostPeriod(ctx, period)
+	if run != nil {
+		log.Printf("Depreciation for %s posted: %d items, %d skipped, %d failed, total %.2f",
+			run.PeriodStart.Format("2006-01"), run.Posted, run.Skipped, run.Failed, run.TotalAmount)
+	}
+	return err
+}
+
+// RunCommand implements the "depreciate" CLI subcommand:
+//
+//	go run main.go depreciate [-period 2026-09]
+func (j *DepreciationJob) RunCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("depreciate", flag.ContinueOnError)
+	period := flags.String("period", "", "month to post as YYYY-MM, defaults to the last completed month")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *period == "" {
+		return j.Run(ctx)
+	}
+	month, err := time.Parse("2006-01", *period)
+	if err != nil {
+		return errors.New("invalid period, please use YYYY-MM")
+	}
+	return j.PostPeriod(ctx, month)
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+)
+
+// MaintenanceJob keeps the next-due dates of maintenance plans in step with
+// the items they cover
+type MaintenanceJob struct {
+	MaintenanceService *services.MaintenanceService
+}
+
+func NewMaintenanceJob(service *services.MaintenanceService) *MaintenanceJob {
+	return &MaintenanceJob{MaintenanceService: service}
+}
+
+func (j *MaintenanceJob) Run(ctx context.Context) error {
+	added, removed, err := j.MaintenanceService.RefreshSchedules(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Maintenance schedules refreshed: %d added, %d removed", added, removed)
+	return nil
+}
@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+)
+
+// ReplacementJob keeps the is_replacement_needed flags of items up to date
+type ReplacementJob struct {
+	ItemService *services.ItemService
+}
+
+func NewReplacementJob(service *services.ItemService) *ReplacementJob {
+	return &ReplacementJob{ItemService: service}
+}
+
+func (j *ReplacementJob) Run(ctx context.Context) error {
+	updated, err := j.ItemService.RecalculateReplacementFlags(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Replacement flags recalculated: %d items changed", updated)
+	return nil
+}
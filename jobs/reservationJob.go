@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+)
+
+// ReservationJob checks items out to their reservation holders when the
+// reservations begin
+type ReservationJob struct {
+	ItemReservationService *services.ItemReservationService
+}
+
+func NewReservationJob(service *services.ItemReservationService) *ReservationJob {
+	return &ReservationJob{ItemReservationService: service}
+}
+
+func (j *ReservationJob) Run(ctx context.Context) error {
+	handedOff, missed, err := j.ItemReservationService.StartReservations(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if handedOff > 0 || missed > 0 {
+		log.Printf("Reservations started: %d checked out, %d missed", handedOff, missed)
+	}
+	return nil
+}
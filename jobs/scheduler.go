@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// JobFunc is a unit of background work run by the Scheduler
+type JobFunc func(ctx context.Context) error
+
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs on a fixed interval inside the server
+type Scheduler struct {
+	jobs []scheduledJob
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers a job that runs once at start-up and then on each interval
+func (s *Scheduler) Every(interval time.Duration, name string, run JobFunc) {
+	s.jobs = append(s.jobs, scheduledJob{name: name, interval: interval, run: run})
+}
+
+// Start launches every registered job in its own goroutine. The jobs stop
+// when ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job scheduledJob) {
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job scheduledJob) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Job %s panicked: %v", job.name, p)
+		}
+	}()
+
+	start := time.Now()
+	if err := job.run(ctx); err != nil {
+		log.Printf("Job %s failed: %v", job.name, err.Error())
+		return
+	}
+	log.Printf("Job %s finished in %v", job.name, time.Since(start))
+}
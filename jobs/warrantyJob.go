@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/notifications"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
+)
+
+// WarrantyJob alerts about warranties that are about to run out, once per
+// warranty end date
+type WarrantyJob struct {
+	ItemService *services.ItemService
+	Notifier    notifications.Notifier
+}
+
+func NewWarrantyJob(service *services.ItemService, notifier notifications.Notifier) *WarrantyJob {
+	return &WarrantyJob{ItemService: service, Notifier: notifier}
+}
+
+func (j *WarrantyJob) Run(ctx context.Context) error {
+	sent, err := j.ItemService.NotifyExpiringWarranties(ctx, j.Notifier, models.DefaultWarrantyAlertDays)
+	if err != nil {
+		return err
+	}
+	log.Printf("Warranty alerts sent: %d", sent)
+	return nil
+}
@@ -1,14 +1,57 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/database"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/jobs"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/notifications"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/routers"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
 )
 
 func main() {
-	r := routers.NewRouter()
+	db := database.NewPostgresDB()
+	repositories.QueryTimeout = database.QueryTimeout()
+	uow := repositories.NewUnitOfWork(db)
+
+	depreciationService := services.NewDepreciationService(uow, repositories.NewItemInvestmentRepository(), repositories.NewDepreciationRepository())
+	depreciationJob := jobs.NewDepreciationJob(depreciationService)
+
+	itemService := services.NewItemService(uow, repositories.NewItemRepository(), repositories.NewItemInvestmentRepository(), repositories.NewLocationRepository(),
+		repositories.NewMaintenancePlanRepository(), repositories.NewItemTransitionRepository(), repositories.NewItemAssignmentRepository())
+	replacementJob := jobs.NewReplacementJob(itemService)
+	warrantyJob := jobs.NewWarrantyJob(itemService, notifications.NewNotifier())
+
+	maintenanceService := services.NewMaintenanceService(uow, repositories.NewItemRepository(), repositories.NewCategoryRepository(), repositories.NewMaintenanceRepository(),
+		repositories.NewMaintenancePlanRepository())
+	maintenanceJob := jobs.NewMaintenanceJob(maintenanceService)
+
+	reservationService := services.NewItemReservationService(uow, itemService, repositories.NewItemAssignmentRepository(), repositories.NewItemReservationRepository())
+	reservationJob := jobs.NewReservationJob(reservationService)
+
+	// "go run main.go depreciate [-period YYYY-MM]" posts one period and exits
+	if len(os.Args) > 1 && os.Args[1] == "depreciate" {
+		if err := depreciationJob.RunCommand(context.Background(), os.Args[2:]); err != nil {
+			log.Fatalf("Error posting depreciation: %v\n", err.Error())
+		}
+		return
+	}
+
+	scheduler := jobs.NewScheduler()
+	scheduler.Every(24*time.Hour, "depreciation", depreciationJob.Run)
+	scheduler.Every(time.Hour, "replacement", replacementJob.Run)
+	scheduler.Every(time.Minute, "reservations", reservationJob.Run)
+	scheduler.Every(time.Hour, "maintenance", maintenanceJob.Run)
+	scheduler.Every(24*time.Hour, "warranty", warrantyJob.Run)
+	scheduler.Start(context.Background())
+
+	r := routers.NewRouter(db)
 
 	fs := http.FileServer(http.Dir("./static"))
 	r.Handle("/static/*", http.StripPrefix("/static/", fs))
@@ -1,33 +1,66 @@
 package middlewares
 
 import (
+	"context"
 	"net/http"
 
-	"github.com/Safiramdhn/project-app-inventaris-golang-safira/database"
-	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/services"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/utils"
 )
 
 var JsonResp = &utils.JSONResponse{}
 
-func AuthMiddleware(next http.Handler) http.Handler {
+type contextKey string
+
+const sessionContextKey contextKey = "session"
+
+// AuthMiddleware checks the session cookie of a request against the shared
+// auth service instead of opening a database connection per request.
+type AuthMiddleware struct {
+	AuthService *services.AuthService
+}
+
+func NewAuthMiddleware(authService *services.AuthService) *AuthMiddleware {
+	return &AuthMiddleware{AuthService: authService}
+}
+
+// Authenticate rejects requests without a valid session and stores the
+// session in the request context for the handlers.
+func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Extract and validate token from request header or cookie
 		cookie, err := r.Cookie("token")
 		if err != nil || cookie == nil {
 			http.Redirect(w, r, "/login", http.StatusUnauthorized)
+			return
 		}
 
-		db := database.NewPostgresDB()
-		authRepo := repositories.NewAuthRepository(db)
-		authService := services.NewAuthService(authRepo)
-		_, err = authService.GetSession(cookie.Value)
+		session, err := m.AuthService.GetSession(r.Context(), cookie.Value)
 		if err != nil {
 			JsonResp.SendError(w, http.StatusUnauthorized, "Invalid token", err.Error())
 			return
 		}
 
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionContextKey, session)))
+	})
+}
+
+// RequireAdmin only lets admins through. It must run after Authenticate.
+func (m *AuthMiddleware) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := SessionFromContext(r.Context())
+		if session == nil || session.Role != models.RoleAdmin {
+			JsonResp.SendError(w, http.StatusForbidden, "Forbidden", "admin role required")
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
+
+// SessionFromContext returns the session stored by Authenticate, or nil.
+func SessionFromContext(ctx context.Context) *models.Session {
+	session, _ := ctx.Value(sessionContextKey).(*models.Session)
+	return session
+}
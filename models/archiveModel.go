@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Values of status_enum. Deletes are soft: they set StatusDeleted and keep
+// the row until it is purged.
+const (
+	StatusActive  = "active"
+	StatusDeleted = "deleted"
+)
+
+// DefaultPurgeRetentionDays is how long deleted records are kept before a
+// purge removes them for good.
+const DefaultPurgeRetentionDays = 30
+
+type PurgeResult struct {
+	Cutoff             time.Time `json:"cutoff"`
+	ItemsPurged        int       `json:"items_purged"`
+	CategoriesPurged   int       `json:"categories_purged"`
+	PhotosRemoved      int       `json:"photos_removed"`
+	AttachmentsRemoved int       `json:"attachments_removed"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+const (
+	AuditOpen   = "open"
+	AuditClosed = "closed"
+)
+
+// AuditSession is a physical stock-take of either a location or a category,
+// each including their subdivisions
+type AuditSession struct {
+	ID                int        `json:"id"`
+	Name              string     `json:"name"`
+	LocationID        int        `json:"location_id,omitempty"`
+	LocationPath      string     `json:"location_path,omitempty"`
+	CategoryID        int        `json:"category_id,omitempty"`
+	CategoryPath      string     `json:"category_path,omitempty"`
+	Status            string     `json:"status"`
+	Notes             string     `json:"notes,omitempty"`
+	StartedBy         int        `json:"started_by,omitempty"`
+	StartedByUsername string     `json:"started_by_username,omitempty"`
+	StartedAt         time.Time  `json:"started_at"`
+	ClosedBy          int        `json:"closed_by,omitempty"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"`
+	ScanCount         int        `json:"scan_count"`
+}
+
+// AuditScanRequest records an item seen during an audit by its scanned asset
+// tag or serial number. LocationID is where the item was found and defaults
+// to the audited location.
+type AuditScanRequest struct {
+	Tag        string `json:"tag"`
+	Serial     string `json:"serial"`
+	LocationID int    `json:"location_id"`
+	Condition  string `json:"condition"`
+}
+
+// AuditScan is one item seen during an audit. ItemID is zero when the
+// scanned code matched no item.
+type AuditScan struct {
+	ID                int       `json:"id"`
+	SessionID         int       `json:"session_id"`
+	ItemID            int       `json:"item_id,omitempty"`
+	ItemName          string    `json:"item_name,omitempty"`
+	ScannedCode       string    `json:"scanned_code"`
+	LocationID        int       `json:"location_id,omitempty"`
+	LocationPath      string    `json:"location_path,omitempty"`
+	Condition         string    `json:"condition,omitempty"`
+	ScannedBy         int       `json:"scanned_by,omitempty"`
+	ScannedByUsername string    `json:"scanned_by_username,omitempty"`
+	ScannedAt         time.Time `json:"scanned_at"`
+}
+
+// Kinds of audit finding
+const (
+	FindingMissing    = "missing"    // expected but not seen
+	FindingUnexpected = "unexpected" // seen but not expected, or not a known item
+	FindingMislocated = "mislocated" // expected and seen, but somewhere else
+)
+
+// AuditFinding is a difference between what an audit saw and what items says
+type AuditFinding struct {
+	Kind                 string         `json:"kind"`
+	ItemID               int            `json:"item_id,omitempty"`
+	ItemName             string         `json:"item_name,omitempty"`
+	Code                 string         `json:"code"`
+	ExpectedLocationID   int            `json:"expected_location_id,omitempty"`
+	ExpectedLocationPath string         `json:"expected_location_path,omitempty"`
+	FoundLocationID      int            `json:"found_location_id,omitempty"`
+	FoundLocationPath    string         `json:"found_location_path,omitempty"`
+	Condition            string         `json:"condition,omitempty"`
+	LifecycleState       LifecycleState `json:"lifecycle_state,omitempty"`
+}
+
+// AuditReconciliation compares an audit with the items in its scope. It is
+// a preview that can still change while the session is open.
+type AuditReconciliation struct {
+	Session       AuditSession   `json:"session"`
+	Preview       bool           `json:"preview"`
+	ExpectedCount int            `json:"expected_count"`
+	SeenCount     int            `json:"seen_count"`
+	Missing       []AuditFinding `json:"missing"`
+	Unexpected    []AuditFinding `json:"unexpected"`
+	Mislocated    []AuditFinding `json:"mislocated"`
+}
+
+// MarkLostRequest moves the missing items of a closed audit to lost
+type MarkLostRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SkippedItem is an item a bulk operation left alone, and why
+type SkippedItem struct {
+	ItemID int    `json:"item_id"`
+	Reason string `json:"reason"`
+}
+
+type MarkLostResult struct {
+	Transitioned []ItemTransition `json:"transitioned"`
+	Skipped      []SkippedItem    `json:"skipped"`
+}
@@ -1,7 +1,51 @@
 package models
 
+import (
+	"fmt"
+	"time"
+)
+
 type Category struct {
-	ID          int    `json:"category_id,omitempty"`
-	Name        string `json:"category_name,omitempty"`
-	Description string `json:"category_description,omitempty"`
+	ID                 int                `json:"category_id,omitempty"`
+	Name               string             `json:"category_name,omitempty"`
+	Description        string             `json:"category_description,omitempty"`
+	ParentID           *int               `json:"parent_id,omitempty"` // 0 on update moves the category to the top level
+	Path               string             `json:"category_path,omitempty"`
+	DepreciationMethod DepreciationMethod `json:"depreciation_method,omitempty"`
+	UsefulLifeYears    int                `json:"useful_life_years,omitempty"`
+	SalvageValue       float64            `json:"salvage_value,omitempty"`
+	TagPrefix          string             `json:"tag_prefix,omitempty"` // inherited from the parent when empty
+
+	ReplacementAfterDays    int     `json:"replacement_after_days,omitempty"`
+	ReplacementMinBookValue float64 `json:"replacement_min_book_value,omitempty"`
+
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// CategoryNode is a category in the category tree. The item count and the
+// investment totals include the items of every subcategory.
+type CategoryNode struct {
+	Category
+	ItemCount       int             `json:"item_count"`
+	TotalInvestment float64         `json:"total_investment"`
+	CurrentValue    float64         `json:"current_value"`
+	Children        []*CategoryNode `json:"children"`
+}
+
+// CategoryDeleteOptions says what happens to the items and subcategories of
+// a category being deleted. Without either option the delete is refused.
+type CategoryDeleteOptions struct {
+	ReassignTo int  // move them to this category first
+	Force      bool // archive them together with the category
+}
+
+// CategoryInUseError is returned when a category still has active items or
+// subcategories and no delete option says what to do with them.
+type CategoryInUseError struct {
+	ItemCount        int `json:"item_count"`
+	SubcategoryCount int `json:"subcategory_count"`
+}
+
+func (e *CategoryInUseError) Error() string {
+	return fmt.Sprintf("category still has %d active items and %d subcategories", e.ItemCount, e.SubcategoryCount)
 }
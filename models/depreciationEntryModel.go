@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// DepreciationEntry is one posted line of the depreciation ledger
+type DepreciationEntry struct {
+	ID                 int       `json:"id,omitempty"`
+	ItemID             int       `json:"item_id,omitempty"`
+	PeriodStart        time.Time `json:"period_start"`
+	PeriodEnd          time.Time `json:"period_end"`
+	OpeningValue       float64   `json:"opening_value"`
+	DepreciationAmount float64   `json:"depreciation_amount"`
+	ClosingValue       float64   `json:"closing_value"`
+	PostedAt           time.Time `json:"posted_at,omitempty"`
+}
+
+// DepreciationRun summarizes a posting run for one period
+type DepreciationRun struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Posted      int       `json:"posted"`
+	Skipped     int       `json:"skipped"`
+	Failed      int       `json:"failed"`
+	TotalAmount float64   `json:"total_amount"`
+}
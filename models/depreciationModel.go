@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+type DepreciationMethod string
+
+const (
+	StraightLine     DepreciationMethod = "straight_line"
+	DecliningBalance DepreciationMethod = "declining_balance"
+	DoubleDeclining  DepreciationMethod = "double_declining"
+	SumOfYearsDigits DepreciationMethod = "sum_of_years_digits"
+)
+
+// Defaults used when neither the item nor its category configures depreciation
+const (
+	DefaultDepreciationMethod = StraightLine
+	DefaultUsefulLifeYears    = 5
+)
+
+// Granularity of a projected depreciation schedule
+const (
+	ScheduleMonthly = "month"
+	ScheduleYearly  = "year"
+)
+
+// IsValid reports whether m is one of the supported depreciation methods
+func (m DepreciationMethod) IsValid() bool {
+	switch m {
+	case StraightLine, DecliningBalance, DoubleDeclining, SumOfYearsDigits:
+		return true
+	}
+	return false
+}
+
+// DepreciationPolicy is the effective depreciation setup of a single item,
+// with category defaults already applied where the item has no override.
+type DepreciationPolicy struct {
+	ItemID          int                `json:"item_id,omitempty"`
+	CategoryID      int                `json:"category_id,omitempty"`
+	CategoryName    string             `json:"category,omitempty"`
+	Method          DepreciationMethod `json:"depreciation_method"`
+	Cost            float64            `json:"cost"`
+	SalvageValue    float64            `json:"salvage_value"`
+	UsefulLifeYears int                `json:"useful_life_years"`
+	Rate            float64            `json:"depreciation_rate,omitempty"`
+	PurchaseDate    time.Time          `json:"purchase_date"`
+}
+
+// DepreciationScheduleRow is one projected period of an item's depreciation
+type DepreciationScheduleRow struct {
+	Period                  int       `json:"period"`
+	PeriodStart             time.Time `json:"period_start"`
+	PeriodEnd               time.Time `json:"period_end"`
+	OpeningValue            float64   `json:"opening_value"`
+	DepreciationAmount      float64   `json:"depreciation_amount"`
+	AccumulatedDepreciation float64   `json:"accumulated_depreciation"`
+	ClosingValue            float64   `json:"closing_value"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type ItemAssignment struct {
+	ID                int        `json:"id"`
+	ItemID            int        `json:"item_id"`
+	ItemName          string     `json:"item_name,omitempty"`
+	AssigneeID        int        `json:"assignee_id"`
+	AssigneeUsername  string     `json:"assignee_username,omitempty"`
+	CheckedOutBy      int        `json:"checked_out_by,omitempty"`
+	CheckedOutAt      time.Time  `json:"checked_out_at"`
+	DueDate           *time.Time `json:"due_date,omitempty"`
+	CheckoutCondition string     `json:"checkout_condition,omitempty"`
+	CheckedInBy       int        `json:"checked_in_by,omitempty"`
+	CheckedInAt       *time.Time `json:"checked_in_at,omitempty"`
+	ReturnCondition   string     `json:"return_condition,omitempty"`
+	DaysOverdue       int        `json:"days_overdue,omitempty"`
+}
+
+// CheckoutRequest lends an item. AssigneeID defaults to the user making the
+// request and DueDate is an optional YYYY-MM-DD date.
+type CheckoutRequest struct {
+	AssigneeID int    `json:"assignee_id"`
+	DueDate    string `json:"due_date"`
+	Condition  string `json:"condition"`
+}
+
+type CheckinRequest struct {
+	Condition string `json:"condition"`
+}
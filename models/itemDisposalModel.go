@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// DisposalMethod is how an item left the organization
+type DisposalMethod string
+
+const (
+	DisposalSold     DisposalMethod = "sold"
+	DisposalScrapped DisposalMethod = "scrapped"
+	DisposalDonated  DisposalMethod = "donated"
+)
+
+func (m DisposalMethod) IsValid() bool {
+	switch m {
+	case DisposalSold, DisposalScrapped, DisposalDonated:
+		return true
+	}
+	return false
+}
+
+// ItemDisposal records an item being sold, scrapped or donated. The item's
+// name and category are copied so the record outlives a purge of the item.
+type ItemDisposal struct {
+	ID                 int            `json:"id"`
+	ItemID             int            `json:"item_id,omitempty"`
+	ItemName           string         `json:"item_name"`
+	CategoryName       string         `json:"category"`
+	DisposedOn         time.Time      `json:"disposed_on"`
+	Method             DisposalMethod `json:"method"`
+	Proceeds           float64        `json:"proceeds"`
+	BookValue          float64        `json:"book_value"`
+	GainLoss           float64        `json:"gain_loss"`
+	Notes              string         `json:"notes,omitempty"`
+	ApprovedBy         int            `json:"approved_by,omitempty"`
+	ApprovedByUsername string         `json:"approved_by_username,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+}
+
+// DisposalRequest is the body of POST /api/items/{id}/dispose. DisposedOn is
+// a YYYY-MM-DD date and defaults to today.
+type DisposalRequest struct {
+	DisposedOn string         `json:"disposed_on"`
+	Method     DisposalMethod `json:"method"`
+	Proceeds   float64        `json:"proceeds"`
+	Notes      string         `json:"notes"`
+}
+
+// DisposalTotals sums the money columns of a set of disposals
+type DisposalTotals struct {
+	Count     int     `json:"count"`
+	BookValue float64 `json:"book_value"`
+	Proceeds  float64 `json:"proceeds"`
+	GainLoss  float64 `json:"gain_loss"`
+}
+
+// DisposalReport is the response of GET /api/reports/disposals
+type DisposalReport struct {
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Disposals []ItemDisposal `json:"disposals"`
+	Totals    DisposalTotals `json:"totals"`
+}
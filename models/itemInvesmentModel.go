@@ -3,13 +3,58 @@ package models
 import "time"
 
 type ItemInvestment struct {
-	ID                   int       `json:"id,omitempty"`
-	ItemID               int       `json:"item_id,omitempty"`
-	ItemName             string    `json:"item_name,omitempty"`
-	InitialPrice         float64   `json:"initial_price,omitempty"`
-	CurrentValue         float64   `json:"current_value,omitempty"`
-	DepreciationRate     float64   `json:"depreciation_rate,omitempty"`
-	LastDepreciationDate time.Time `json:"last_depreciation_date,omitempty"`
-	TotalInvestment      float64   `json:"total_investment,omitempty"`
-	DepricatedValue      float64   `json:"depricated_value,omitempty"`
+	ID                   int                `json:"id,omitempty"`
+	ItemID               int                `json:"item_id,omitempty"`
+	ItemName             string             `json:"item_name,omitempty"`
+	InitialPrice         float64            `json:"initial_price,omitempty"`
+	CurrentValue         float64            `json:"current_value,omitempty"`
+	DepreciationRate     float64            `json:"depreciation_rate,omitempty"`
+	DepreciationMethod   DepreciationMethod `json:"depreciation_method,omitempty"`
+	UsefulLifeYears      int                `json:"useful_life_years,omitempty"`
+	SalvageValue         float64            `json:"salvage_value,omitempty"`
+	LastDepreciationDate time.Time          `json:"last_depreciation_date,omitempty"`
+	TotalInvestment      float64            `json:"total_investment,omitempty"`
+	DepricatedValue      float64            `json:"depricated_value,omitempty"`
+
+	// Maintenance spend on top of the purchase price
+	MaintenanceCost      float64 `json:"maintenance_cost"`
+	TotalCostOfOwnership float64 `json:"total_cost_of_ownership"`
+
+	// Set once the item is disposed of; the value is frozen from then on
+	DisposedOn *time.Time `json:"disposed_on,omitempty"`
+
+	// Computed from the depreciation policy for today, whereas CurrentValue
+	// is the value last posted to the ledger
+	BookValueToday float64 `json:"book_value_today"`
+
+	AsOf                    *time.Time                `json:"as_of,omitempty"`
+	BookValueAsOf           *float64                  `json:"book_value_as_of,omitempty"`
+	AccumulatedDepreciation *float64                  `json:"accumulated_depreciation,omitempty"`
+	Schedule                []DepreciationScheduleRow `json:"schedule,omitempty"`
+}
+
+// Ways of grouping an investment summary
+const (
+	GroupByCategory     = "category"
+	GroupByPurchaseYear = "purchase_year"
+	GroupByAgeBucket    = "age_bucket"
+)
+
+// InvestmentSummaryGroup aggregates the investment of one group of items
+type InvestmentSummaryGroup struct {
+	Group                   string  `json:"group"`
+	CategoryID              int     `json:"category_id,omitempty"`
+	ItemCount               int     `json:"item_count"`
+	AcquisitionCost         float64 `json:"acquisition_cost"`
+	CurrentValue            float64 `json:"current_value"`
+	AccumulatedDepreciation float64 `json:"accumulated_depreciation"`
+}
+
+// InvestmentSummary is the response of GET /api/items/investment/summary
+type InvestmentSummary struct {
+	AsOf           time.Time                `json:"as_of"`
+	GroupBy        string                   `json:"group_by"`
+	IncludeDeleted bool                     `json:"include_deleted"`
+	Groups         []InvestmentSummaryGroup `json:"groups"`
+	Totals         InvestmentSummaryGroup   `json:"totals"`
 }
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// LifecycleState is where an item is in its life, from being requested to
+// being disposed of. Deleting an item is tracked separately by status.
+type LifecycleState string
+
+const (
+	LifecycleRequested   LifecycleState = "requested"
+	LifecycleOrdered     LifecycleState = "ordered"
+	LifecycleInStock     LifecycleState = "in_stock"
+	LifecycleInUse       LifecycleState = "in_use"
+	LifecycleUnderRepair LifecycleState = "under_repair"
+	LifecycleLost        LifecycleState = "lost"
+	LifecycleRetired     LifecycleState = "retired"
+	LifecycleDisposed    LifecycleState = "disposed"
+)
+
+func (s LifecycleState) IsValid() bool {
+	switch s {
+	case LifecycleRequested, LifecycleOrdered, LifecycleInStock, LifecycleInUse,
+		LifecycleUnderRepair, LifecycleLost, LifecycleRetired, LifecycleDisposed:
+		return true
+	}
+	return false
+}
+
+// IsAvailable reports whether an item in this state can be lent out
+func (s LifecycleState) IsAvailable() bool {
+	return s == LifecycleInStock || s == LifecycleInUse
+}
+
+type ItemTransition struct {
+	ID                     int            `json:"id"`
+	ItemID                 int            `json:"item_id"`
+	FromState              LifecycleState `json:"from_state,omitempty"` // empty for the state an item was created in
+	ToState                LifecycleState `json:"to_state"`
+	Reason                 string         `json:"reason"`
+	TransitionedBy         int            `json:"transitioned_by,omitempty"`
+	TransitionedByUsername string         `json:"transitioned_by_username,omitempty"`
+	TransitionedAt         time.Time      `json:"transitioned_at"`
+}
+
+type TransitionRequest struct {
+	State  LifecycleState `json:"state"`
+	Reason string         `json:"reason"`
+}
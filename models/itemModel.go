@@ -2,15 +2,61 @@ package models
 
 import "time"
 
+// DefaultAssetTagPrefix is used for items in categories without a tag prefix
+const DefaultAssetTagPrefix = "AST"
+
 type Item struct {
-	ID                  int       `json:"id,omitempty"`
-	Name                string    `json:"name,omitempty"`
-	CategoryID          int       `json:"category_id,omitempty"`
-	CategoryName        string    `json:"category,omitempty"`
-	PhotoURL            string    `json:"photo_url,omitempty"`
-	Price               float64   `json:"price,omitempty"`
-	PurchaseDate        time.Time `json:"purchase_date,omitempty"`
-	TotalUsageDays      int       `json:"total_usage_days,omitempty"`
-	IsReplacementNeeded bool      `json:"is_replacement_needed,omitempty"`
-	DepreciatedRate     int       `json:"depresiated_rate,omitempty"`
+	ID                  int                `json:"id,omitempty"`
+	Name                string             `json:"name,omitempty"`
+	AssetTag            string             `json:"asset_tag,omitempty"`
+	SerialNumber        string             `json:"serial_number,omitempty"`
+	Manufacturer        string             `json:"manufacturer,omitempty"`
+	Model               string             `json:"model,omitempty"`
+	CategoryID          int                `json:"category_id,omitempty"`
+	CategoryName        string             `json:"category,omitempty"`
+	CategoryPath        string             `json:"category_path,omitempty"`
+	LocationID          int                `json:"location_id,omitempty"`
+	LocationPath        string             `json:"location_path,omitempty"`
+	PhotoURL            string             `json:"photo_url,omitempty"`
+	Price               float64            `json:"price,omitempty"`
+	PurchaseDate        time.Time          `json:"purchase_date,omitempty"`
+	TotalUsageDays      int                `json:"total_usage_days,omitempty"`
+	IsReplacementNeeded bool               `json:"is_replacement_needed,omitempty"`
+	LifecycleState      LifecycleState     `json:"lifecycle_state,omitempty"`
+	DepreciatedRate     int                `json:"depresiated_rate,omitempty"`
+	DepreciationMethod  DepreciationMethod `json:"depreciation_method,omitempty"`
+	UsefulLifeYears     int                `json:"useful_life_years,omitempty"`
+	SalvageValue        float64            `json:"salvage_value,omitempty"`
+
+	ReplacementAfterDays    int                `json:"replacement_after_days,omitempty"`
+	ReplacementMinBookValue float64            `json:"replacement_min_book_value,omitempty"`
+	WarrantyProvider        string             `json:"warranty_provider,omitempty"`
+	WarrantyEndsOn          *time.Time         `json:"warranty_ends_on,omitempty"`
+	WarrantyCoverage        string             `json:"warranty_coverage,omitempty"`
+	InsurancePolicyRefs     []string           `json:"insurance_policy_refs,omitempty"`
+	Replacement             *ReplacementStatus `json:"replacement,omitempty"`
+	OverdueMaintenance      []MaintenanceDue   `json:"overdue_maintenance,omitempty"`
+	DeletedAt               *time.Time         `json:"deleted_at,omitempty"`
+}
+
+// ItemFilter narrows an item listing. A zero CategoryID matches every
+// category; IncludeDescendants also matches the items of its subcategories.
+// A LocationID matches the items at that location and all its sublocations.
+// A State matches the items in that lifecycle state. Search matches part of
+// the name, asset tag or serial number.
+type ItemFilter struct {
+	Status             string // StatusActive when empty
+	CategoryID         int
+	IncludeDescendants bool
+	LocationID         int
+	State              LifecycleState // any state when empty
+	Search             string
+}
+
+// ItemLookup is the response of GET /api/items/lookup: the scanned item with
+// who has it and where it is kept
+type ItemLookup struct {
+	Item              Item            `json:"item"`
+	CurrentAssignment *ItemAssignment `json:"current_assignment"`
+	Location          *Location       `json:"location"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type ItemMovement struct {
+	ID               int       `json:"id"`
+	ItemID           int       `json:"item_id"`
+	FromLocationID   int       `json:"from_location_id,omitempty"`
+	FromLocationPath string    `json:"from_location,omitempty"`
+	ToLocationID     int       `json:"to_location_id"`
+	ToLocationPath   string    `json:"to_location,omitempty"`
+	MovedBy          int       `json:"moved_by,omitempty"`
+	MovedByUsername  string    `json:"moved_by_username,omitempty"`
+	Reason           string    `json:"reason"`
+	MovedAt          time.Time `json:"moved_at"`
+}
+
+type MoveItemRequest struct {
+	LocationID int    `json:"location_id"`
+	Reason     string `json:"reason"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	ReservationBooked     = "booked"
+	ReservationCancelled  = "cancelled"
+	ReservationCheckedOut = "checked_out"
+	ReservationMissed     = "missed"
+)
+
+type ItemReservation struct {
+	ID           int        `json:"id"`
+	ItemID       int        `json:"item_id"`
+	ItemName     string     `json:"item_name,omitempty"`
+	UserID       int        `json:"user_id"`
+	Username     string     `json:"username,omitempty"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at"`
+	Purpose      string     `json:"purpose,omitempty"`
+	Status       string     `json:"status"`
+	AssignmentID int        `json:"assignment_id,omitempty"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// ReservationRequest books an item from StartsAt until EndsAt
+type ReservationRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Purpose  string    `json:"purpose"`
+}
+
+// ItemAvailability tells whether an item is free for a whole time window,
+// listing the reservations and any check-out that get in the way.
+type ItemAvailability struct {
+	ItemID       int               `json:"item_id"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	Available    bool              `json:"available"`
+	Reservations []ItemReservation `json:"reservations"`
+	CheckedOut   *ItemAssignment   `json:"checked_out,omitempty"`
+}
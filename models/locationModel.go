@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// LocationType is a level of the location hierarchy:
+// site > building > floor > room.
+type LocationType string
+
+const (
+	LocationSite     LocationType = "site"
+	LocationBuilding LocationType = "building"
+	LocationFloor    LocationType = "floor"
+	LocationRoom     LocationType = "room"
+)
+
+// Rank returns the depth of the type in the hierarchy, starting at 1 for a
+// site, or 0 for an unknown type.
+func (t LocationType) Rank() int {
+	switch t {
+	case LocationSite:
+		return 1
+	case LocationBuilding:
+		return 2
+	case LocationFloor:
+		return 3
+	case LocationRoom:
+		return 4
+	}
+	return 0
+}
+
+func (t LocationType) IsValid() bool {
+	return t.Rank() > 0
+}
+
+type Location struct {
+	ID          int          `json:"location_id,omitempty"`
+	Name        string       `json:"location_name,omitempty"`
+	Type        LocationType `json:"location_type,omitempty"`
+	ParentID    *int         `json:"parent_id,omitempty"` // required for everything below a site
+	Description string       `json:"description,omitempty"`
+	Path        string       `json:"location_path,omitempty"`
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty"`
+}
+
+// LocationSummary counts the items at a location and all its sublocations
+type LocationSummary struct {
+	Location        Location  `json:"location"`
+	AsOf            time.Time `json:"as_of"`
+	ItemCount       int       `json:"item_count"`
+	TotalInvestment float64   `json:"total_investment"`
+	BookValue       float64   `json:"book_value"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// MaintenanceType tells planned servicing apart from repairs
+type MaintenanceType string
+
+const (
+	MaintenancePreventive MaintenanceType = "preventive"
+	MaintenanceCorrective MaintenanceType = "corrective"
+)
+
+func (t MaintenanceType) IsValid() bool {
+	return t == MaintenancePreventive || t == MaintenanceCorrective
+}
+
+type MaintenanceRecord struct {
+	ID                 int             `json:"id"`
+	ItemID             int             `json:"item_id"`
+	Type               MaintenanceType `json:"maintenance_type"`
+	PerformedOn        time.Time       `json:"performed_on"`
+	Vendor             string          `json:"vendor,omitempty"`
+	Cost               float64         `json:"cost"`
+	Description        string          `json:"description"`
+	AttachmentURLs     []string        `json:"attachment_urls"`
+	RecordedBy         int             `json:"recorded_by,omitempty"`
+	RecordedByUsername string          `json:"recorded_by_username,omitempty"`
+	PlanID             int             `json:"plan_id,omitempty"` // the plan this record completes
+	IsWarrantyClaim    bool            `json:"is_warranty_claim"`
+	UnderWarranty      bool            `json:"under_warranty"` // done while the item's warranty was still running
+	CreatedAt          time.Time       `json:"created_at"`
+}
+
+// MaintenancePlan is recurring maintenance for one item, or for every item
+// of a category and its subcategories
+type MaintenancePlan struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ItemID       int       `json:"item_id,omitempty"`
+	ItemName     string    `json:"item_name,omitempty"`
+	CategoryID   int       `json:"category_id,omitempty"`
+	CategoryPath string    `json:"category_path,omitempty"`
+	IntervalDays int       `json:"interval_days"`
+	Description  string    `json:"description,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MaintenanceDue is the next occurrence of a plan for one item. A negative
+// DaysUntilDue means it is overdue.
+type MaintenanceDue struct {
+	PlanID       int        `json:"plan_id"`
+	PlanName     string     `json:"plan_name"`
+	ItemID       int        `json:"item_id"`
+	ItemName     string     `json:"item_name"`
+	CategoryPath string     `json:"category_path,omitempty"`
+	LocationPath string     `json:"location_path,omitempty"`
+	IntervalDays int        `json:"interval_days"`
+	LastDoneOn   *time.Time `json:"last_done_on,omitempty"`
+	NextDueOn    time.Time  `json:"next_due_on"`
+	DaysUntilDue int        `json:"days_until_due"`
+	Overdue      bool       `json:"overdue"`
+}
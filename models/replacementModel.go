@@ -0,0 +1,34 @@
+package models
+
+// DefaultReplacementAfterDays applies when a category has no replacement policy
+const DefaultReplacementAfterDays = 100
+
+// Rules that can flag an item for replacement
+const (
+	ReplacementRuleAge       = "age"
+	ReplacementRuleBookValue = "book_value"
+)
+
+// ReplacementPolicy is the effective replacement policy of an item, with the
+// category policy applied where the item has no override.
+type ReplacementPolicy struct {
+	AfterDays    int     `json:"replacement_after_days"`
+	MinBookValue float64 `json:"min_book_value,omitempty"`
+	Source       string  `json:"source"`
+}
+
+// ReplacementStatus explains whether and why an item needs replacement
+type ReplacementStatus struct {
+	Policy      ReplacementPolicy `json:"policy"`
+	BookValue   float64           `json:"book_value"`
+	DaysLeft    int               `json:"days_left"`
+	TriggeredBy []string          `json:"triggered_by,omitempty"`
+	Reason      string            `json:"reason"`
+}
+
+// ReplacementCandidate carries what is needed to evaluate one item
+type ReplacementCandidate struct {
+	Item         Item
+	Depreciation DepreciationPolicy
+	Policy       ReplacementPolicy
+}
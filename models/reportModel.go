@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// AssetRegisterRow is one item line of the fixed-asset register
+type AssetRegisterRow struct {
+	ItemID                  int                `json:"item_id"`
+	ItemName                string             `json:"item_name"`
+	CategoryID              int                `json:"category_id"`
+	CategoryName            string             `json:"category"`
+	PurchaseDate            time.Time          `json:"purchase_date"`
+	Cost                    float64            `json:"cost"`
+	DepreciationMethod      DepreciationMethod `json:"depreciation_method"`
+	UsefulLifeYears         int                `json:"useful_life_years"`
+	AccumulatedDepreciation float64            `json:"accumulated_depreciation"`
+	NetBookValue            float64            `json:"net_book_value"`
+	Status                  string             `json:"status"` // the lifecycle state, e.g. in_use or disposed
+}
+
+// AssetRegisterTotals sums the money columns of a set of register rows
+type AssetRegisterTotals struct {
+	ItemCount               int     `json:"item_count"`
+	Cost                    float64 `json:"cost"`
+	AccumulatedDepreciation float64 `json:"accumulated_depreciation"`
+	NetBookValue            float64 `json:"net_book_value"`
+}
+
+// AssetRegisterCategory holds the register rows of one category
+type AssetRegisterCategory struct {
+	CategoryID   int                 `json:"category_id"`
+	CategoryName string              `json:"category"`
+	Rows         []AssetRegisterRow  `json:"rows"`
+	Subtotal     AssetRegisterTotals `json:"subtotal"`
+}
+
+// AssetRegister is the fixed-asset register as of a date
+type AssetRegister struct {
+	AsOf       time.Time               `json:"as_of"`
+	Categories []AssetRegisterCategory `json:"categories"`
+	GrandTotal AssetRegisterTotals     `json:"grand_total"`
+}
+
+// AssetRecord pairs an item's register details with its depreciation policy
+// and the date it was disposed of, if it was
+type AssetRecord struct {
+	Row        AssetRegisterRow
+	Policy     DepreciationPolicy
+	DisposedOn *time.Time
+}
+
+// ForecastItem is an item expected to reach its replacement threshold
+type ForecastItem struct {
+	ItemID          int       `json:"item_id"`
+	ItemName        string    `json:"item_name"`
+	CategoryName    string    `json:"category"`
+	ReplacementDate time.Time `json:"replacement_date"`
+	TriggeredBy     string    `json:"triggered_by"`
+	OriginalPrice   float64   `json:"original_price"`
+	EstimatedCost   float64   `json:"estimated_cost"`
+}
+
+// ForecastMonth lists the replacements falling due in one month
+type ForecastMonth struct {
+	Month         string         `json:"month"`
+	ItemCount     int            `json:"item_count"`
+	EstimatedCost float64        `json:"estimated_cost"`
+	Items         []ForecastItem `json:"items"`
+}
+
+// ForecastCategory totals the forecast replacements of one category
+type ForecastCategory struct {
+	CategoryID    int     `json:"category_id"`
+	CategoryName  string  `json:"category"`
+	CategoryPath  string  `json:"category_path"`
+	ItemCount     int     `json:"item_count"`
+	EstimatedCost float64 `json:"estimated_cost"`
+}
+
+// ReplacementForecast is the response of GET /api/reports/replacement-forecast
+type ReplacementForecast struct {
+	From               time.Time          `json:"from"`
+	To                 time.Time          `json:"to"`
+	Months             int                `json:"months"`
+	InflationRate      float64            `json:"inflation_rate"`
+	ByMonth            []ForecastMonth    `json:"by_month"`
+	ByCategory         []ForecastCategory `json:"by_category"`
+	ItemCount          int                `json:"item_count"`
+	TotalEstimatedCost float64            `json:"total_estimated_cost"`
+}
@@ -6,6 +6,7 @@ type User struct {
 	ID           int    `json:"user_id,omitempty"`
 	Username     string `json:"username,omitempty"`
 	Email        string `json:"email,omitempty"`
+	Role         string `json:"role,omitempty"`
 	PasswordHash string
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
@@ -16,3 +17,8 @@ type UserDTO struct {
 	Email    string `json:"email,omitempty"`
 	Password string `json:"password,omitempty"`
 }
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
@@ -9,6 +9,7 @@ type LoginRequest struct {
 
 type Session struct {
 	UserID       int       `json:"user_id"`
+	Role         string    `json:"role,omitempty"`
 	SessionToken string    `json:"session_token"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	IsActive     bool      `json:"is_active,omitempty"`
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// DefaultWarrantyAlertDays is how far ahead warranty expiries are reported
+// when no period is given
+const DefaultWarrantyAlertDays = 30
+
+// WarrantyExpiry is an item whose warranty is about to run out
+type WarrantyExpiry struct {
+	ItemID              int       `json:"item_id"`
+	ItemName            string    `json:"item_name"`
+	CategoryPath        string    `json:"category_path,omitempty"`
+	LocationPath        string    `json:"location_path,omitempty"`
+	WarrantyProvider    string    `json:"warranty_provider,omitempty"`
+	WarrantyEndsOn      time.Time `json:"warranty_ends_on"`
+	WarrantyCoverage    string    `json:"warranty_coverage,omitempty"`
+	InsurancePolicyRefs []string  `json:"insurance_policy_refs,omitempty"`
+	DaysLeft            int       `json:"days_left"`
+}
@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Notification is a message for the people looking after the inventory
+type Notification struct {
+	Subject string      `json:"subject"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+	SentAt  time.Time   `json:"sent_at"`
+}
+
+// Notifier delivers notifications. Implementations must be safe for use by
+// several jobs at once.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NewNotifier returns a WebhookNotifier when NOTIFY_WEBHOOK_URL is set and a
+// LogNotifier otherwise
+func NewNotifier() Notifier {
+	if url := os.Getenv("NOTIFY_WEBHOOK_URL"); url != "" {
+		return NewWebhookNotifier(url)
+	}
+	return LogNotifier{}
+}
+
+// LogNotifier writes notifications to the server log
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	log.Printf("Notification: %s - %s", notification.Subject, notification.Message)
+	return nil
+}
+
+// WebhookNotifier posts each notification as JSON to a URL, such as a chat
+// channel's incoming webhook
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := n.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+type AuditRepository interface {
+	CreateSession(ctx context.Context, db DBTX, session *models.AuditSession) error
+	FindSessions(ctx context.Context, db DBTX) ([]models.AuditSession, error)
+	FindSession(ctx context.Context, db DBTX, id int) (*models.AuditSession, error)
+	LockSession(ctx context.Context, db DBTX, id int, exclusive bool) (string, error)
+	CreateScan(ctx context.Context, db DBTX, scan *models.AuditScan) error
+	FindScans(ctx context.Context, db DBTX, sessionID int) ([]models.AuditScan, error)
+	PreviewFindings(ctx context.Context, db DBTX, sessionID int) ([]models.AuditFinding, int, int, error)
+	Close(ctx context.Context, db DBTX, sessionID, userID int) error
+	FindFindings(ctx context.Context, db DBTX, sessionID int) ([]models.AuditFinding, int, int, error)
+	FindMissingItemIDs(ctx context.Context, db DBTX, sessionID int) ([]int, error)
+}
+
+// auditFindingsCTE works out the findings of audit session $1 from its scans
+// and the items in its scope. Items that are requested, ordered, lost or
+// disposed are not expected to be on the shelf. Only the latest scan of an
+// item counts, so a rescan corrects the location or condition. A scan at a
+// location matches items recorded there or in any of its sublocations.
+const auditFindingsCTE = `audit_session AS (
+		SELECT id, location_id, category_id FROM audit_sessions WHERE id = $1
+	),
+	scope_locations AS (
+		SELECT location_id AS id FROM audit_session WHERE location_id IS NOT NULL
+		UNION
+		SELECT l.id FROM locations l JOIN scope_locations s ON l.parent_id = s.id
+	),
+	scope_categories AS (
+		SELECT category_id AS id FROM audit_session WHERE category_id IS NOT NULL
+		UNION
+		SELECT c.id FROM categories c JOIN scope_categories s ON c.parent_id = s.id
+	),
+	expected AS (
+		SELECT i.id, i.name, i.asset_tag, i.location_id FROM items i
+		WHERE i.status = 'active' AND i.lifecycle_state NOT IN ('requested', 'ordered', 'lost', 'disposed')
+		AND (i.location_id IN (SELECT id FROM scope_locations) OR i.category_id IN (SELECT id FROM scope_categories))
+	),
+	last_scans AS (
+		SELECT DISTINCT ON (COALESCE('item:' || s.item_id, 'code:' || s.scanned_code))
+		s.item_id, s.scanned_code, s.location_id, s.condition
+		FROM audit_scans s WHERE s.session_id = $1
+		ORDER BY COALESCE('item:' || s.item_id, 'code:' || s.scanned_code), s.scanned_at DESC, s.id DESC
+	),
+	scan_location_scope AS (
+		SELECT DISTINCT location_id AS root_id, location_id AS id FROM audit_scans WHERE session_id = $1 AND location_id IS NOT NULL
+		UNION
+		SELECT s.root_id, l.id FROM locations l JOIN scan_location_scope s ON l.parent_id = s.id
+	),
+	findings AS (
+		SELECT 'missing'::audit_finding_enum AS kind, e.id AS item_id, e.name AS item_name, e.asset_tag AS code,
+		e.location_id AS expected_location_id, NULL::int AS found_location_id, NULL::text AS condition
+		FROM expected e WHERE NOT EXISTS (SELECT 1 FROM last_scans ls WHERE ls.item_id = e.id)
+		UNION ALL
+		SELECT 'unexpected', ls.item_id, i.name, ls.scanned_code, i.location_id, ls.location_id, ls.condition
+		FROM last_scans ls LEFT JOIN items i ON i.id = ls.item_id
+		WHERE ls.item_id IS NULL OR ls.item_id NOT IN (SELECT id FROM expected)
+		UNION ALL
+		SELECT 'mislocated', e.id, e.name, ls.scanned_code, e.location_id, ls.location_id, ls.condition
+		FROM expected e JOIN last_scans ls ON ls.item_id = e.id
+		WHERE ls.location_id IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM scan_location_scope sl WHERE sl.root_id = ls.location_id AND sl.id = e.location_id)
+	)`
+
+// auditFindingsSelect reads the rows of a findings CTE with their location
+// paths and the current lifecycle state of their items
+const auditFindingsSelect = `SELECT f.kind, COALESCE(f.item_id, 0), COALESCE(f.item_name, ''), f.code,
+				COALESCE(f.expected_location_id, 0), COALESCE(el.path, ''), COALESCE(f.found_location_id, 0), COALESCE(fl.path, ''),
+				COALESCE(f.condition, ''), COALESCE(i.lifecycle_state::text, '')
+				FROM findings f
+				LEFT JOIN location_paths el ON el.id = f.expected_location_id
+				LEFT JOIN location_paths fl ON fl.id = f.found_location_id
+				LEFT JOIN items i ON i.id = f.item_id
+				ORDER BY f.kind, f.item_name, f.code`
+
+// auditSessionColumns selects a session (alias s) with its scope paths and starter
+const auditSessionColumns = `s.id, s.name, COALESCE(s.location_id, 0), COALESCE(lp.path, ''), COALESCE(s.category_id, 0), COALESCE(cp.path, ''),
+	s.status, COALESCE(s.notes, ''), COALESCE(s.started_by, 0), COALESCE(u.username, ''), s.started_at, COALESCE(s.closed_by, 0), s.closed_at,
+	(SELECT COUNT(*) FROM audit_scans a WHERE a.session_id = s.id)`
+
+const auditSessionJoins = ` FROM audit_sessions s
+				LEFT JOIN location_paths lp ON lp.id = s.location_id
+				LEFT JOIN category_paths cp ON cp.id = s.category_id
+				LEFT JOIN users u ON u.id = s.started_by`
+
+func scanAuditSession(row rowScanner, session *models.AuditSession) error {
+	return row.Scan(&session.ID, &session.Name, &session.LocationID, &session.LocationPath, &session.CategoryID, &session.CategoryPath,
+		&session.Status, &session.Notes, &session.StartedBy, &session.StartedByUsername, &session.StartedAt, &session.ClosedBy, &session.ClosedAt,
+		&session.ScanCount)
+}
+
+type auditRepository struct{}
+
+func NewAuditRepository() AuditRepository {
+	return &auditRepository{}
+}
+
+// CreateSession implements AuditRepository.
+func (a *auditRepository) CreateSession(ctx context.Context, db DBTX, session *models.AuditSession) error {
+	if session == nil {
+		return errors.New("audit session cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO audit_sessions (name, location_id, category_id, notes, started_by)
+				VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, ''), NULLIF($5, 0)) RETURNING id, status, started_at`
+	err := db.QueryRowContext(ctx, sqlStatement, session.Name, session.LocationID, session.CategoryID, session.Notes, session.StartedBy).
+		Scan(&session.ID, &session.Status, &session.StartedAt)
+	if err != nil {
+		log.Printf("Error inserting audit session: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FindSessions implements AuditRepository. Open sessions come first, then
+// the most recent.
+func (a *auditRepository) FindSessions(ctx context.Context, db DBTX) ([]models.AuditSession, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `, ` + locationPathsCTE + `
+				SELECT ` + auditSessionColumns + auditSessionJoins + `
+				ORDER BY s.status = 'closed', s.started_at DESC, s.id DESC`
+	rows, err := db.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.AuditSession{}
+	for rows.Next() {
+		var session models.AuditSession
+		if err := scanAuditSession(rows, &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// FindSession implements AuditRepository.
+func (a *auditRepository) FindSession(ctx context.Context, db DBTX, id int) (*models.AuditSession, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var session models.AuditSession
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `, ` + locationPathsCTE + `
+				SELECT ` + auditSessionColumns + auditSessionJoins + ` WHERE s.id = $1`
+	err := scanAuditSession(db.QueryRowContext(ctx, sqlStatement, id), &session)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("audit session does not exist")
+	} else if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// LockSession implements AuditRepository. It returns the status of the
+// session and locks it until the surrounding transaction ends: shared for
+// recording scans, so auditors do not wait on each other, or exclusive for
+// closing, which then waits for the scans in flight.
+func (a *auditRepository) LockSession(ctx context.Context, db DBTX, id int, exclusive bool) (string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT status FROM audit_sessions WHERE id = $1 FOR SHARE`
+	if exclusive {
+		sqlStatement = `SELECT status FROM audit_sessions WHERE id = $1 FOR UPDATE`
+	}
+	var status string
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", errors.New("audit session does not exist")
+	} else if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// CreateScan implements AuditRepository.
+func (a *auditRepository) CreateScan(ctx context.Context, db DBTX, scan *models.AuditScan) error {
+	if scan == nil {
+		return errors.New("audit scan cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO audit_scans (session_id, item_id, scanned_code, location_id, condition, scanned_by)
+				VALUES ($1, NULLIF($2, 0), $3, NULLIF($4, 0), NULLIF($5, ''), NULLIF($6, 0)) RETURNING id, scanned_at`
+	err := db.QueryRowContext(ctx, sqlStatement, scan.SessionID, scan.ItemID, scan.ScannedCode, scan.LocationID, scan.Condition, scan.ScannedBy).
+		Scan(&scan.ID, &scan.ScannedAt)
+	if err != nil {
+		log.Printf("Error inserting audit scan: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FindScans implements AuditRepository. Scans are returned oldest first.
+func (a *auditRepository) FindScans(ctx context.Context, db DBTX, sessionID int) ([]models.AuditScan, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ` + locationPathsCTE + `
+				SELECT s.id, s.session_id, COALESCE(s.item_id, 0), COALESCE(i.name, ''), s.scanned_code, COALESCE(s.location_id, 0), COALESCE(lp.path, ''),
+				COALESCE(s.condition, ''), COALESCE(s.scanned_by, 0), COALESCE(u.username, ''), s.scanned_at
+				FROM audit_scans s
+				LEFT JOIN items i ON i.id = s.item_id
+				LEFT JOIN location_paths lp ON lp.id = s.location_id
+				LEFT JOIN users u ON u.id = s.scanned_by
+				WHERE s.session_id = $1
+				ORDER BY s.scanned_at, s.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scans := []models.AuditScan{}
+	for rows.Next() {
+		var scan models.AuditScan
+		err := rows.Scan(&scan.ID, &scan.SessionID, &scan.ItemID, &scan.ItemName, &scan.ScannedCode, &scan.LocationID, &scan.LocationPath,
+			&scan.Condition, &scan.ScannedBy, &scan.ScannedByUsername, &scan.ScannedAt)
+		if err != nil {
+			return nil, err
+		}
+		scans = append(scans, scan)
+	}
+	return scans, rows.Err()
+}
+
+// PreviewFindings implements AuditRepository. It works out the findings of
+// a session as things stand, along with how many items were expected and
+// how many distinct items or codes were seen.
+func (a *auditRepository) PreviewFindings(ctx context.Context, db DBTX, sessionID int) ([]models.AuditFinding, int, int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var expectedCount, seenCount int
+	countStatement := `WITH RECURSIVE ` + auditFindingsCTE + `
+				SELECT (SELECT COUNT(*) FROM expected), (SELECT COUNT(*) FROM last_scans)`
+	if err := db.QueryRowContext(ctx, countStatement, sessionID).Scan(&expectedCount, &seenCount); err != nil {
+		return nil, 0, 0, err
+	}
+
+	sqlStatement := `WITH RECURSIVE ` + locationPathsCTE + `, ` + auditFindingsCTE + `
+				` + auditFindingsSelect
+	findings, err := queryAuditFindings(ctx, db, sqlStatement, sessionID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return findings, expectedCount, seenCount, nil
+}
+
+// Close implements AuditRepository. It stores the findings of a session
+// together with its counts, so the reconciliation no longer changes when
+// the items do, and marks the session closed by userID.
+func (a *auditRepository) Close(ctx context.Context, db DBTX, sessionID, userID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	insertStatement := `WITH RECURSIVE ` + auditFindingsCTE + `
+				INSERT INTO audit_findings (session_id, kind, item_id, item_name, code, expected_location_id, found_location_id, condition)
+				SELECT $1, kind, item_id, item_name, code, expected_location_id, found_location_id, condition FROM findings`
+	if _, err := db.ExecContext(ctx, insertStatement, sessionID); err != nil {
+		log.Printf("Error storing audit findings: %v", err.Error())
+		return err
+	}
+
+	updateStatement := `WITH RECURSIVE ` + auditFindingsCTE + `
+				UPDATE audit_sessions SET status = 'closed', closed_by = NULLIF($2, 0), closed_at = NOW(),
+				expected_count = (SELECT COUNT(*) FROM expected), seen_count = (SELECT COUNT(*) FROM last_scans)
+				WHERE id = $1 AND status = 'open'`
+	result, err := db.ExecContext(ctx, updateStatement, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("audit session is not open")
+	}
+	return nil
+}
+
+// FindFindings implements AuditRepository. It returns the findings and
+// counts stored when a session was closed.
+func (a *auditRepository) FindFindings(ctx context.Context, db DBTX, sessionID int) ([]models.AuditFinding, int, int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var expectedCount, seenCount int
+	countStatement := `SELECT COALESCE(expected_count, 0), COALESCE(seen_count, 0) FROM audit_sessions WHERE id = $1`
+	err := db.QueryRowContext(ctx, countStatement, sessionID).Scan(&expectedCount, &seenCount)
+	if err == sql.ErrNoRows {
+		return nil, 0, 0, errors.New("audit session does not exist")
+	} else if err != nil {
+		return nil, 0, 0, err
+	}
+
+	sqlStatement := `WITH RECURSIVE ` + locationPathsCTE + `,
+				findings AS (
+					SELECT kind, item_id, item_name, code, expected_location_id, found_location_id, condition
+					FROM audit_findings WHERE session_id = $1
+				)
+				` + auditFindingsSelect
+	findings, err := queryAuditFindings(ctx, db, sqlStatement, sessionID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return findings, expectedCount, seenCount, nil
+}
+
+// FindMissingItemIDs implements AuditRepository. It returns the items a
+// closed session found missing that are still active.
+func (a *auditRepository) FindMissingItemIDs(ctx context.Context, db DBTX, sessionID int) ([]int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT f.item_id FROM audit_findings f
+				JOIN items i ON i.id = f.item_id
+				WHERE f.session_id = $1 AND f.kind = 'missing' AND i.status = 'active'
+				ORDER BY f.item_id`
+	rows, err := db.QueryContext(ctx, sqlStatement, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func queryAuditFindings(ctx context.Context, db DBTX, sqlStatement string, sessionID int) ([]models.AuditFinding, error) {
+	rows, err := db.QueryContext(ctx, sqlStatement, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	findings := []models.AuditFinding{}
+	for rows.Next() {
+		var finding models.AuditFinding
+		err := rows.Scan(&finding.Kind, &finding.ItemID, &finding.ItemName, &finding.Code,
+			&finding.ExpectedLocationID, &finding.ExpectedLocationPath, &finding.FoundLocationID, &finding.FoundLocationPath,
+			&finding.Condition, &finding.LifecycleState)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, finding)
+	}
+	return findings, rows.Err()
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log"
@@ -10,71 +11,41 @@ import (
 )
 
 type AuthRepository interface {
-	Register(userDTO *models.UserDTO) (*models.User, error)
-	Login(loginRequest *models.LoginRequest) (*models.User, error)
-	CreateSession(sessionInput *models.Session) (*models.Session, error)
-	ValidateSession(sessionToken string) (*models.Session, error)
-	InvalidateSession(sessionToken string) error
-	RefreshSession(oldSessionToken string) (*models.Session, error)
+	Register(ctx context.Context, db DBTX, userDTO *models.UserDTO) (*models.User, error)
+	Login(ctx context.Context, db DBTX, loginRequest *models.LoginRequest) (*models.User, error)
+	CreateSession(ctx context.Context, db DBTX, sessionInput *models.Session) (*models.Session, error)
+	ValidateSession(ctx context.Context, db DBTX, sessionToken string) (*models.Session, error)
+	InvalidateSession(ctx context.Context, db DBTX, sessionToken string) error
+	RefreshSession(ctx context.Context, db DBTX, oldSessionToken string) (*models.Session, error)
 }
 
-type authRepository struct {
-	DB *sql.DB
-}
+type authRepository struct{}
 
-func NewAuthRepository(db *sql.DB) AuthRepository {
-	return &authRepository{DB: db}
+func NewAuthRepository() AuthRepository {
+	return &authRepository{}
 }
 
 // CreateSession implements AuthRepository.
-func (a *authRepository) CreateSession(sessionInput *models.Session) (*models.Session, error) {
-	tx, err := a.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting register transaction: %v\n", err.Error())
-		return nil, err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (a *authRepository) CreateSession(ctx context.Context, db DBTX, sessionInput *models.Session) (*models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 
 	sqlStatement := `INSERT INTO sessions (user_id, session_token, expires_at) VALUES ($1, $2, $3) RETURNING session_token`
-	err = tx.QueryRow(sqlStatement, sessionInput.UserID, sessionInput.SessionToken, sessionInput.ExpiresAt).Scan(&sessionInput.SessionToken)
+	err := db.QueryRowContext(ctx, sqlStatement, sessionInput.UserID, sessionInput.SessionToken, sessionInput.ExpiresAt).Scan(&sessionInput.SessionToken)
 	if err != nil {
 		log.Printf("Error inserting session: %v\n", err.Error())
 		return nil, err
 	}
-	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing register transaction: %v\n", err.Error())
-		return nil, err
-	}
 	return sessionInput, nil
 }
 
 // InvalidateSession implements AuthRepository.
-func (a *authRepository) InvalidateSession(sessionToken string) error {
-	tx, err := a.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting invalidate transaction: %v\n", err.Error())
-		return err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (a *authRepository) InvalidateSession(ctx context.Context, db DBTX, sessionToken string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 
 	updateStatement := `UPDATE sessions SET is_active = false WHERE session_token = $1`
-	result, err := tx.Exec(updateStatement, sessionToken)
+	result, err := db.ExecContext(ctx, updateStatement, sessionToken)
 	if err != nil {
 		log.Printf("Error updating session: %v\n", err.Error())
 		return err
@@ -89,19 +60,17 @@ func (a *authRepository) InvalidateSession(sessionToken string) error {
 		log.Println("Session not found")
 		return errors.New("session not found")
 	}
-
-	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing validate session transaction: %v\n", err.Error())
-		return err
-	}
 	return nil
 }
 
 // Login implements AuthRepository.
-func (a *authRepository) Login(loginRequest *models.LoginRequest) (*models.User, error) {
-	sqlStatement := `SELECT id, username, email, password_hash FROM users WHERE username=$1`
+func (a *authRepository) Login(ctx context.Context, db DBTX, loginRequest *models.LoginRequest) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT id, username, email, role, password_hash FROM users WHERE username=$1`
 	var user models.User
-	err := a.DB.QueryRow(sqlStatement, loginRequest.Username).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash)
+	err := db.QueryRowContext(ctx, sqlStatement, loginRequest.Username).Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.PasswordHash)
 	if err == sql.ErrNoRows {
 		log.Println("User not found")
 		return nil, errors.New("user not found")
@@ -113,50 +82,39 @@ func (a *authRepository) Login(loginRequest *models.LoginRequest) (*models.User,
 }
 
 // RefreshSession implements AuthRepository.
-func (a *authRepository) RefreshSession(oldSessionToken string) (*models.Session, error) {
+func (a *authRepository) RefreshSession(ctx context.Context, db DBTX, oldSessionToken string) (*models.Session, error) {
 	panic("unimplemented")
 }
 
 // Register implements AuthRepository.
-func (a *authRepository) Register(userDTO *models.UserDTO) (*models.User, error) {
-	tx, err := a.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting register transaction: %v\n", err.Error())
-		return nil, err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-		}
-	}()
+func (a *authRepository) Register(ctx context.Context, db DBTX, userDTO *models.UserDTO) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 
 	user := models.User{}
-	sqlStatement := `INSERT INTO users (username, email, password_hash) VALUES ($1, $2, $3) RETURNING id, email`
-	err = tx.QueryRow(sqlStatement, userDTO.Username, userDTO.Email, userDTO.Password).Scan(&user.ID, &user.Email)
+	sqlStatement := `INSERT INTO users (username, email, password_hash) VALUES ($1, $2, $3) RETURNING id, email, role`
+	err := db.QueryRowContext(ctx, sqlStatement, userDTO.Username, userDTO.Email, userDTO.Password).Scan(&user.ID, &user.Email, &user.Role)
 	if err != nil {
 		log.Printf("Error inserting user: %v\n", err.Error())
 		return nil, err
 	}
-	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing register transaction: %v\n", err.Error())
-		return nil, err
-	}
 	return &user, nil
 }
 
 // ValidateSession implements AuthRepository.
-func (a *authRepository) ValidateSession(sessionToken string) (*models.Session, error) {
+func (a *authRepository) ValidateSession(ctx context.Context, db DBTX, sessionToken string) (*models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	// Prepare SQL statement
-	sqlStatement := `SELECT session_token, user_id, expires_at FROM sessions WHERE session_token = $1`
+	sqlStatement := `SELECT s.session_token, s.user_id, u.role, s.expires_at FROM sessions s
+				JOIN users u ON s.user_id = u.id
+				WHERE s.session_token = $1`
 
 	var session models.Session
 
 	// Execute the query
-	err := a.DB.QueryRow(sqlStatement, sessionToken).Scan(&session.SessionToken, &session.UserID, &session.ExpiresAt)
+	err := db.QueryRowContext(ctx, sqlStatement, sessionToken).Scan(&session.SessionToken, &session.UserID, &session.Role, &session.ExpiresAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("invalid session token")
@@ -168,7 +126,7 @@ func (a *authRepository) ValidateSession(sessionToken string) (*models.Session,
 	// Check if the session has expired
 	if session.ExpiresAt.Before(time.Now()) {
 		// Invalidate the expired session
-		if err := a.InvalidateSession(session.SessionToken); err != nil {
+		if err := a.InvalidateSession(ctx, db, session.SessionToken); err != nil {
 			log.Printf("Error invalidating expired session: %v\n", err.Error())
 			return nil, err
 		}
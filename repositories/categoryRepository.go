@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -13,93 +14,97 @@ import (
 )
 
 type CategoryRepository interface {
-	Create(categoryInput *models.Category) (*models.Category, error)
-	Update(categoryInput *models.Category) (*models.Category, error)
-	Delete(id int) error
-	FindAll() ([]models.Category, error)
-	FindByID(id int) (*models.Category, error)
+	Create(ctx context.Context, db DBTX, categoryInput *models.Category) (*models.Category, error)
+	Update(ctx context.Context, db DBTX, categoryInput *models.Category) (*models.Category, error)
+	Delete(ctx context.Context, db DBTX, id int) error
+	FindAll(ctx context.Context, db DBTX, status string) ([]models.Category, error)
+	FindByID(ctx context.Context, db DBTX, id int) (*models.Category, error)
+	FindAncestorIDs(ctx context.Context, db DBTX, id int) ([]int, error)
+	LockHierarchy(ctx context.Context, db DBTX) error
+	LockCategory(ctx context.Context, db DBTX, id int) error
+	CountUsage(ctx context.Context, db DBTX, id int) (int, int, error)
+	Reassign(ctx context.Context, db DBTX, fromID, toID int) (int, error)
+	ArchiveDescendants(ctx context.Context, db DBTX, id int) (int, error)
+	Restore(ctx context.Context, db DBTX, id int) (*models.Category, error)
+	PurgeDeleted(ctx context.Context, db DBTX, cutoff time.Time) (int, error)
 }
 
-type categoryRepository struct {
-	DB *sql.DB
+type categoryRepository struct{}
+
+// categoryPathsCTE builds the breadcrumb of every category, such as
+// "IT Equipment > Laptops > Developer Laptops". Use it after WITH RECURSIVE.
+const categoryPathsCTE = `category_paths AS (
+		SELECT id, name::text AS path FROM categories WHERE parent_id IS NULL
+		UNION
+		SELECT c.id, cp.path || ' > ' || c.name FROM categories c JOIN category_paths cp ON c.parent_id = cp.id
+	)`
+
+// categoryColumns selects a category (alias c) joined to category_paths (alias cp)
+const categoryColumns = `c.id, c.name, c.description, COALESCE(c.parent_id, 0), cp.path, c.depreciation_method, c.useful_life_years, c.salvage_value,
+	c.replacement_after_days, COALESCE(c.replacement_min_book_value, 0), COALESCE(c.tag_prefix, ''), c.deleted_at`
+
+func scanCategory(row rowScanner, category *models.Category) error {
+	var parentID int
+	err := row.Scan(&category.ID, &category.Name, &category.Description, &parentID, &category.Path, &category.DepreciationMethod, &category.UsefulLifeYears, &category.SalvageValue,
+		&category.ReplacementAfterDays, &category.ReplacementMinBookValue, &category.TagPrefix, &category.DeletedAt)
+	if err == nil && parentID != 0 {
+		category.ParentID = &parentID
+	}
+	return err
 }
 
 // NewCategoryRepository creates a new instance of CategoryRepository
-func NewCategoryRepository(db *sql.DB) CategoryRepository {
-	return &categoryRepository{DB: db}
+func NewCategoryRepository() CategoryRepository {
+	return &categoryRepository{}
 }
 
 // Create implements CategoryRepository.
-func (c *categoryRepository) Create(categoryInput *models.Category) (*models.Category, error) {
-	tx, err := c.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err.Error())
-		return nil, err
-	}
+func (c *categoryRepository) Create(ctx context.Context, db DBTX, categoryInput *models.Category) (*models.Category, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // Re-panic after rollback
-		} else if err != nil {
-			log.Printf("Rolling back transaction due to error: %v", err.Error())
-			tx.Rollback()
-		}
-	}()
-
-	sqlStatement := `INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id`
-	err = tx.QueryRow(sqlStatement, categoryInput.Name, categoryInput.Description).Scan(&categoryInput.ID)
+	sqlStatement := `INSERT INTO categories (name, description, parent_id, depreciation_method, useful_life_years, salvage_value, replacement_after_days, replacement_min_book_value, tag_prefix)
+				VALUES ($1, $2, NULLIF($3, 0), COALESCE(NULLIF($4, '')::depreciation_method_enum, 'straight_line'), COALESCE(NULLIF($5, 0), 5), $6, COALESCE(NULLIF($7, 0), 100), NULLIF($8, 0),
+				NULLIF($9, ''))
+				RETURNING id`
+	err := db.QueryRowContext(ctx, sqlStatement, categoryInput.Name, categoryInput.Description, categoryInput.ParentID,
+		categoryInput.DepreciationMethod, categoryInput.UsefulLifeYears, categoryInput.SalvageValue,
+		categoryInput.ReplacementAfterDays, categoryInput.ReplacementMinBookValue, categoryInput.TagPrefix).Scan(&categoryInput.ID)
 	if err != nil {
 		log.Printf("Error inserting category: %v", err.Error())
 		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err.Error())
-		return nil, err
-	}
-
 	log.Printf("Inserted category with ID: %d", categoryInput.ID) // Log success
-	return categoryInput, nil
+	return c.FindByID(ctx, db, categoryInput.ID)
 }
 
 // Delete implements CategoryRepository.
-func (c *categoryRepository) Delete(id int) error {
-	tx, err := c.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err.Error())
-		return err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // Re-panic after rollback
-		} else if err != nil {
-			log.Printf("Rolling back transaction due to error: %v", err.Error())
-			tx.Rollback()
-		}
-	}()
+func (c *categoryRepository) Delete(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 
-	sqlStatement := `UPDATE categories SET status = 'deleted' WHERE id = $1`
-	_, err = tx.Exec(sqlStatement, id)
+	sqlStatement := `UPDATE categories SET status = 'deleted', deleted_at = NOW() WHERE id = $1`
+	_, err := db.ExecContext(ctx, sqlStatement, id)
 	if err != nil {
 		return err
 	}
-
-	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err.Error())
-		return err
-	}
-
 	return nil
 }
 
-// FindAll implements CategoryRepository.
-func (c *categoryRepository) FindAll() ([]models.Category, error) {
+// FindAll implements CategoryRepository. It returns the categories with the
+// given status, active or deleted.
+func (c *categoryRepository) FindAll(ctx context.Context, db DBTX, status string) ([]models.Category, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var categories []models.Category
-	sqlStatement := `SELECT id, name, description FROM categories WHERE status = 'active'`
-	rows, err := c.DB.Query(sqlStatement)
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `
+				SELECT ` + categoryColumns + ` FROM categories c
+				JOIN category_paths cp ON cp.id = c.id
+				WHERE c.status = $1
+				ORDER BY cp.path`
+	rows, err := db.QueryContext(ctx, sqlStatement, status)
 	if err != nil {
 		return nil, err
 	}
@@ -107,21 +112,27 @@ func (c *categoryRepository) FindAll() ([]models.Category, error) {
 
 	for rows.Next() {
 		var category models.Category
-		err := rows.Scan(&category.ID, &category.Name, &category.Description)
+		err := scanCategory(rows, &category)
 		if err != nil {
 			return nil, err
 		}
 		categories = append(categories, category)
 	}
 
-	return categories, nil
+	return categories, rows.Err()
 }
 
 // FindByID implements CategoryRepository.
-func (c *categoryRepository) FindByID(id int) (*models.Category, error) {
+func (c *categoryRepository) FindByID(ctx context.Context, db DBTX, id int) (*models.Category, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var category models.Category
-	sqlStatement := `SELECT id, name, description FROM categories WHERE id = $1 AND status = 'active'`
-	err := c.DB.QueryRow(sqlStatement, id).Scan(&category.ID, &category.Name, &category.Description)
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `
+				SELECT ` + categoryColumns + ` FROM categories c
+				JOIN category_paths cp ON cp.id = c.id
+				WHERE c.id = $1 AND c.status = 'active'`
+	err := scanCategory(db.QueryRowContext(ctx, sqlStatement, id), &category)
 	if err == sql.ErrNoRows {
 		return nil, errors.New("category does not exist")
 	} else if err != nil {
@@ -132,22 +143,9 @@ func (c *categoryRepository) FindByID(id int) (*models.Category, error) {
 }
 
 // Update implements CategoryRepository.
-func (c *categoryRepository) Update(categoryInput *models.Category) (*models.Category, error) {
-	tx, err := c.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err.Error())
-		return nil, err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // Re-panic after rollback
-		} else if err != nil {
-			log.Printf("Rolling back transaction due to error: %v", err.Error())
-			tx.Rollback()
-		}
-	}()
+func (c *categoryRepository) Update(ctx context.Context, db DBTX, categoryInput *models.Category) (*models.Category, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 
 	fields := make(map[string]interface{})
 
@@ -159,6 +157,39 @@ func (c *categoryRepository) Update(categoryInput *models.Category) (*models.Cat
 		fields["description"] = categoryInput.Description
 	}
 
+	if categoryInput.ParentID != nil {
+		if *categoryInput.ParentID == 0 {
+			fields["parent_id"] = nil
+		} else {
+			fields["parent_id"] = *categoryInput.ParentID
+		}
+	}
+
+	if categoryInput.DepreciationMethod != "" {
+		fields["depreciation_method"] = categoryInput.DepreciationMethod
+	}
+
+	if categoryInput.UsefulLifeYears != 0 {
+		fields["useful_life_years"] = categoryInput.UsefulLifeYears
+	}
+
+	if categoryInput.SalvageValue != 0 {
+		fields["salvage_value"] = categoryInput.SalvageValue
+	}
+
+	if categoryInput.ReplacementAfterDays != 0 {
+		fields["replacement_after_days"] = categoryInput.ReplacementAfterDays
+	}
+
+	if categoryInput.ReplacementMinBookValue != 0 {
+		fields["replacement_min_book_value"] = categoryInput.ReplacementMinBookValue
+	}
+
+	// Items keep their tags; a new prefix applies to the items created after
+	if categoryInput.TagPrefix != "" {
+		fields["tag_prefix"] = categoryInput.TagPrefix
+	}
+
 	fields["updated_at"] = time.Now()
 	setClauses := []string{}
 	values := []interface{}{}
@@ -173,24 +204,202 @@ func (c *categoryRepository) Update(categoryInput *models.Category) (*models.Cat
 		return nil, errors.New("no fields to update")
 	}
 
-	sqlStatement := fmt.Sprintf("UPDATE categories SET %s WHERE id = $%d AND status = 'active' RETURNING id, name, description",
+	sqlStatement := fmt.Sprintf("UPDATE categories SET %s WHERE id = $%d AND status = 'active' RETURNING id",
 		strings.Join(setClauses, ", "), index)
 	values = append(values, categoryInput.ID)
 
 	// Execute the update query and scan the result
-	var updatedCategory models.Category
-	err = tx.QueryRow(sqlStatement, values...).Scan(&updatedCategory.ID, &updatedCategory.Name, &updatedCategory.Description)
+	var id int
+	err := db.QueryRowContext(ctx, sqlStatement, values...).Scan(&id)
 	if err != nil {
 		log.Printf("Error updating category: %v", err.Error())
 		return nil, err
 	}
 
-	// Commit the transaction
-	if err := tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err.Error())
+	// Return the updated category
+	return c.FindByID(ctx, db, id)
+}
+
+// FindAncestorIDs implements CategoryRepository. It returns id followed by the
+// ids of its parent, grandparent and so on up to the top level.
+func (c *categoryRepository) FindAncestorIDs(ctx context.Context, db DBTX, id int) ([]int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ancestors AS (
+					SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1
+					UNION ALL
+					SELECT c.id, c.parent_id, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+					WHERE a.depth < 100
+				)
+				SELECT id FROM ancestors ORDER BY depth`
+	rows, err := db.QueryContext(ctx, sqlStatement, id)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	// Return the updated category
-	return &updatedCategory, nil
+	var ids []int
+	for rows.Next() {
+		var ancestorID int
+		if err := rows.Scan(&ancestorID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, ancestorID)
+	}
+	return ids, rows.Err()
+}
+
+// LockHierarchy implements CategoryRepository. It serializes changes to
+// parent_id until the surrounding transaction ends, so two concurrent moves
+// cannot together form a cycle.
+func (c *categoryRepository) LockHierarchy(ctx context.Context, db DBTX) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, `LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`)
+	return err
+}
+
+// LockCategory implements CategoryRepository. It locks an active category
+// until the surrounding transaction ends, which also holds back new items
+// that reference it.
+func (c *categoryRepository) LockCategory(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var lockedID int
+	sqlStatement := `SELECT id FROM categories WHERE id = $1 AND status = 'active' FOR UPDATE`
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		return errors.New("category does not exist")
+	}
+	return err
+}
+
+// CountUsage implements CategoryRepository. It returns the number of active
+// items and active direct subcategories of a category.
+func (c *categoryRepository) CountUsage(ctx context.Context, db DBTX, id int) (int, int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var itemCount, subcategoryCount int
+	sqlStatement := `SELECT (SELECT COUNT(*) FROM items WHERE category_id = $1 AND status = 'active'),
+				(SELECT COUNT(*) FROM categories WHERE parent_id = $1 AND status = 'active')`
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&itemCount, &subcategoryCount)
+	if err != nil {
+		return 0, 0, err
+	}
+	return itemCount, subcategoryCount, nil
+}
+
+// Reassign implements CategoryRepository. It moves the active items and
+// subcategories of one category to another and returns the number of items moved.
+func (c *categoryRepository) Reassign(ctx context.Context, db DBTX, fromID, toID int) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, `UPDATE items SET category_id = $2, updated_at = NOW() WHERE category_id = $1 AND status = 'active'`, fromID, toID)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.ExecContext(ctx, `UPDATE categories SET parent_id = $2, updated_at = NOW() WHERE parent_id = $1 AND status = 'active'`, fromID, toID)
+	if err != nil {
+		return 0, err
+	}
+	return int(moved), nil
+}
+
+// ArchiveDescendants implements CategoryRepository. It soft-deletes the active
+// items of a category and of all its subcategories, then the subcategories
+// themselves, and returns the number of items archived.
+func (c *categoryRepository) ArchiveDescendants(ctx context.Context, db DBTX, id int) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	subtree := `WITH RECURSIVE subtree AS (
+					SELECT id FROM categories WHERE id = $1
+					UNION
+					SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id WHERE c.status = 'active'
+				)`
+	result, err := db.ExecContext(ctx, subtree+`
+				UPDATE items SET status = 'deleted', updated_at = NOW(), deleted_at = NOW()
+				WHERE status = 'active' AND category_id IN (SELECT id FROM subtree)`, id)
+	if err != nil {
+		return 0, err
+	}
+	archived, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.ExecContext(ctx, subtree+`
+				UPDATE categories SET status = 'deleted', updated_at = NOW(), deleted_at = NOW()
+				WHERE id <> $1 AND id IN (SELECT id FROM subtree)`, id)
+	if err != nil {
+		return 0, err
+	}
+	return int(archived), nil
+}
+
+// Restore implements CategoryRepository. Only a deleted category whose
+// parent, if any, is active can be restored.
+func (c *categoryRepository) Restore(ctx context.Context, db DBTX, id int) (*models.Category, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var parentID int
+	sqlStatement := `SELECT COALESCE(parent_id, 0) FROM categories WHERE id = $1 AND status = 'deleted' FOR UPDATE`
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("deleted category does not exist")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if parentID != 0 {
+		// Keep the parent from being deleted while the category comes back
+		var parentStatus string
+		parentStatement := `SELECT status FROM categories WHERE id = $1 FOR SHARE`
+		if err := db.QueryRowContext(ctx, parentStatement, parentID).Scan(&parentStatus); err != nil {
+			return nil, err
+		}
+		if parentStatus != models.StatusActive {
+			return nil, errors.New("parent category is deleted; restore it first")
+		}
+	}
+
+	updateStatement := `UPDATE categories SET status = 'active', deleted_at = NULL, updated_at = NOW() WHERE id = $1`
+	if _, err := db.ExecContext(ctx, updateStatement, id); err != nil {
+		return nil, err
+	}
+	return c.FindByID(ctx, db, id)
+}
+
+// PurgeDeleted implements CategoryRepository. It permanently removes the
+// categories deleted before cutoff that no item, subcategory or audit
+// session refers to anymore, and returns how many were removed.
+func (c *categoryRepository) PurgeDeleted(ctx context.Context, db DBTX, cutoff time.Time) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `DELETE FROM categories c
+				WHERE c.status = 'deleted' AND COALESCE(c.deleted_at, c.updated_at) < $1
+				AND NOT EXISTS (SELECT 1 FROM items i WHERE i.category_id = c.id)
+				AND NOT EXISTS (SELECT 1 FROM categories child WHERE child.parent_id = c.id)
+				AND NOT EXISTS (SELECT 1 FROM audit_sessions a WHERE a.category_id = c.id)`
+	result, err := db.ExecContext(ctx, sqlStatement, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(purged), nil
 }
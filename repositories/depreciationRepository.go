@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+type DepreciationRepository interface {
+	FindPostingCandidates(ctx context.Context, db DBTX, periodEnd time.Time) ([]models.DepreciationPolicy, error)
+	LockInvestment(ctx context.Context, db DBTX, itemId int) (*models.ItemInvestment, error)
+	CreateEntry(ctx context.Context, db DBTX, entry *models.DepreciationEntry) (bool, error)
+	UpdateInvestmentValue(ctx context.Context, db DBTX, itemId int, currentValue float64, depreciationDate time.Time) error
+}
+
+type depreciationRepository struct{}
+
+func NewDepreciationRepository() DepreciationRepository {
+	return &depreciationRepository{}
+}
+
+// FindPostingCandidates implements DepreciationRepository. Disposed items
+// keep the value they were disposed of at.
+func (d *depreciationRepository) FindPostingCandidates(ctx context.Context, db DBTX, periodEnd time.Time) ([]models.DepreciationPolicy, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT ` + depreciationPolicyColumns + ` FROM item_investments inv
+				JOIN items i ON inv.item_id = i.id
+				JOIN categories c ON i.category_id = c.id
+				WHERE i.status = 'active' AND i.lifecycle_state <> 'disposed' AND inv.last_depreciation_date < $1
+				ORDER BY i.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []models.DepreciationPolicy
+	for rows.Next() {
+		var policy models.DepreciationPolicy
+		if err := scanDepreciationPolicy(rows, &policy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// LockInvestment implements DepreciationRepository. It must run inside a
+// transaction so concurrent posting runs serialize on the investment row.
+func (d *depreciationRepository) LockInvestment(ctx context.Context, db DBTX, itemId int) (*models.ItemInvestment, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT id, item_id, current_value, last_depreciation_date FROM item_investments WHERE item_id = $1 FOR UPDATE`
+	var investment models.ItemInvestment
+	err := db.QueryRowContext(ctx, sqlStatement, itemId).Scan(&investment.ID, &investment.ItemID, &investment.CurrentValue, &investment.LastDepreciationDate)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("item investment does not exist")
+	} else if err != nil {
+		return nil, err
+	}
+	return &investment, nil
+}
+
+// CreateEntry implements DepreciationRepository. It reports false without an
+// error when the item already has an entry for the period.
+func (d *depreciationRepository) CreateEntry(ctx context.Context, db DBTX, entry *models.DepreciationEntry) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO depreciation_entries (item_id, period_start, period_end, opening_value, depreciation_amount, closing_value)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (item_id, period_start) DO NOTHING
+				RETURNING id, posted_at`
+	err := db.QueryRowContext(ctx, sqlStatement, entry.ItemID, entry.PeriodStart, entry.PeriodEnd, entry.OpeningValue, entry.DepreciationAmount, entry.ClosingValue).Scan(&entry.ID, &entry.PostedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateInvestmentValue implements DepreciationRepository.
+func (d *depreciationRepository) UpdateInvestmentValue(ctx context.Context, db DBTX, itemId int, currentValue float64, depreciationDate time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `UPDATE item_investments SET current_value = $1, last_depreciation_date = $2 WHERE item_id = $3`
+	_, err := db.ExecContext(ctx, sqlStatement, currentValue, depreciationDate, itemId)
+	return err
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+type ItemAssignmentRepository interface {
+	Create(ctx context.Context, db DBTX, assignment *models.ItemAssignment) error
+	Close(ctx context.Context, db DBTX, assignment *models.ItemAssignment) error
+	FindOpenByItemID(ctx context.Context, db DBTX, itemID int) (*models.ItemAssignment, error)
+	FindOpen(ctx context.Context, db DBTX, assigneeID int, overdueOnly bool) ([]models.ItemAssignment, error)
+}
+
+// assignmentColumns selects an assignment (alias a) with its item (alias i)
+// and assignee (alias u)
+const assignmentColumns = `a.id, a.item_id, i.name, a.assignee_id, u.username, COALESCE(a.checked_out_by, 0), a.checked_out_at, a.due_date,
+	COALESCE(a.checkout_condition, ''), COALESCE(a.checked_in_by, 0), a.checked_in_at, COALESCE(a.return_condition, ''),
+	CASE WHEN a.checked_in_at IS NULL THEN GREATEST(CURRENT_DATE - a.due_date, 0) ELSE 0 END`
+
+func scanAssignment(row rowScanner, assignment *models.ItemAssignment) error {
+	var daysOverdue sql.NullInt64
+	err := row.Scan(&assignment.ID, &assignment.ItemID, &assignment.ItemName, &assignment.AssigneeID, &assignment.AssigneeUsername, &assignment.CheckedOutBy,
+		&assignment.CheckedOutAt, &assignment.DueDate, &assignment.CheckoutCondition, &assignment.CheckedInBy, &assignment.CheckedInAt, &assignment.ReturnCondition,
+		&daysOverdue)
+	assignment.DaysOverdue = int(daysOverdue.Int64)
+	return err
+}
+
+type itemAssignmentRepository struct{}
+
+func NewItemAssignmentRepository() ItemAssignmentRepository {
+	return &itemAssignmentRepository{}
+}
+
+// Create implements ItemAssignmentRepository.
+func (a *itemAssignmentRepository) Create(ctx context.Context, db DBTX, assignment *models.ItemAssignment) error {
+	if assignment == nil {
+		return errors.New("assignment cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO item_assignments (item_id, assignee_id, checked_out_by, due_date, checkout_condition)
+				VALUES ($1, $2, NULLIF($3, 0), $4, NULLIF($5, '')) RETURNING id, checked_out_at`
+	err := db.QueryRowContext(ctx, sqlStatement, assignment.ItemID, assignment.AssigneeID, assignment.CheckedOutBy, assignment.DueDate, assignment.CheckoutCondition).
+		Scan(&assignment.ID, &assignment.CheckedOutAt)
+	if err != nil {
+		log.Printf("Error inserting item assignment: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Close implements ItemAssignmentRepository. It records the check-in of an
+// open assignment.
+func (a *itemAssignmentRepository) Close(ctx context.Context, db DBTX, assignment *models.ItemAssignment) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `UPDATE item_assignments SET checked_in_by = NULLIF($2, 0), checked_in_at = NOW(), return_condition = NULLIF($3, '')
+				WHERE id = $1 AND checked_in_at IS NULL RETURNING checked_in_at`
+	err := db.QueryRowContext(ctx, sqlStatement, assignment.ID, assignment.CheckedInBy, assignment.ReturnCondition).Scan(&assignment.CheckedInAt)
+	if err == sql.ErrNoRows {
+		return errors.New("assignment is already checked in")
+	} else if err != nil {
+		log.Printf("Error closing item assignment: %v", err.Error())
+		return err
+	}
+	assignment.DaysOverdue = 0
+	return nil
+}
+
+// FindOpenByItemID implements ItemAssignmentRepository. It returns nil when
+// the item is not checked out.
+func (a *itemAssignmentRepository) FindOpenByItemID(ctx context.Context, db DBTX, itemID int) (*models.ItemAssignment, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var assignment models.ItemAssignment
+	sqlStatement := `SELECT ` + assignmentColumns + ` FROM item_assignments a
+				JOIN items i ON a.item_id = i.id
+				JOIN users u ON a.assignee_id = u.id
+				WHERE a.item_id = $1 AND a.checked_in_at IS NULL`
+	err := scanAssignment(db.QueryRowContext(ctx, sqlStatement, itemID), &assignment)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// FindOpen implements ItemAssignmentRepository. A zero assigneeID matches
+// every assignee; overdueOnly keeps the assignments past their due date.
+func (a *itemAssignmentRepository) FindOpen(ctx context.Context, db DBTX, assigneeID int, overdueOnly bool) ([]models.ItemAssignment, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT ` + assignmentColumns + ` FROM item_assignments a
+				JOIN items i ON a.item_id = i.id
+				JOIN users u ON a.assignee_id = u.id
+				WHERE a.checked_in_at IS NULL AND ($1 = 0 OR a.assignee_id = $1)
+				AND (NOT $2 OR a.due_date < CURRENT_DATE)
+				ORDER BY a.due_date NULLS LAST, a.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, assigneeID, overdueOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []models.ItemAssignment{}
+	for rows.Next() {
+		var assignment models.ItemAssignment
+		if err := scanAssignment(rows, &assignment); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
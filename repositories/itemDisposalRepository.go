@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+type ItemDisposalRepository interface {
+	Create(ctx context.Context, db DBTX, disposal *models.ItemDisposal) error
+	FindByPeriod(ctx context.Context, db DBTX, from, to time.Time) ([]models.ItemDisposal, error)
+}
+
+type itemDisposalRepository struct{}
+
+func NewItemDisposalRepository() ItemDisposalRepository {
+	return &itemDisposalRepository{}
+}
+
+// Create implements ItemDisposalRepository.
+func (d *itemDisposalRepository) Create(ctx context.Context, db DBTX, disposal *models.ItemDisposal) error {
+	if disposal == nil {
+		return errors.New("disposal cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO item_disposals (item_id, item_name, category_name, disposed_on, method, proceeds, book_value, gain_loss, notes, approved_by)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, 0)) RETURNING id, created_at`
+	err := db.QueryRowContext(ctx, sqlStatement, disposal.ItemID, disposal.ItemName, disposal.CategoryName, disposal.DisposedOn, disposal.Method,
+		disposal.Proceeds, disposal.BookValue, disposal.GainLoss, disposal.Notes, disposal.ApprovedBy).
+		Scan(&disposal.ID, &disposal.CreatedAt)
+	if err != nil {
+		log.Printf("Error inserting item disposal: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FindByPeriod implements ItemDisposalRepository. It returns the disposals
+// dated from from to to, both included, oldest first.
+func (d *itemDisposalRepository) FindByPeriod(ctx context.Context, db DBTX, from, to time.Time) ([]models.ItemDisposal, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT d.id, COALESCE(d.item_id, 0), d.item_name, d.category_name, d.disposed_on, d.method, d.proceeds, d.book_value, d.gain_loss,
+				COALESCE(d.notes, ''), COALESCE(d.approved_by, 0), COALESCE(u.username, ''), d.created_at
+				FROM item_disposals d
+				LEFT JOIN users u ON u.id = d.approved_by
+				WHERE d.disposed_on BETWEEN $1 AND $2
+				ORDER BY d.disposed_on, d.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	disposals := []models.ItemDisposal{}
+	for rows.Next() {
+		var disposal models.ItemDisposal
+		err := rows.Scan(&disposal.ID, &disposal.ItemID, &disposal.ItemName, &disposal.CategoryName, &disposal.DisposedOn, &disposal.Method,
+			&disposal.Proceeds, &disposal.BookValue, &disposal.GainLoss, &disposal.Notes, &disposal.ApprovedBy, &disposal.ApprovedByUsername, &disposal.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		disposals = append(disposals, disposal)
+	}
+	return disposals, rows.Err()
+}
@@ -1,46 +1,198 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"log"
+	"time"
 
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
 )
 
 type ItemInvestmentRepository interface {
-	CountAll() (*models.ItemInvestment, error)
-	FindByItemId(id int) (models.ItemInvestment, error)
+	Create(ctx context.Context, db DBTX, investment *models.ItemInvestment) error
+	CountAll(ctx context.Context, db DBTX) (*models.ItemInvestment, error)
+	FindByItemId(ctx context.Context, db DBTX, id int) (models.ItemInvestment, error)
+	FindDepreciationPolicy(ctx context.Context, db DBTX, itemId int) (*models.DepreciationPolicy, error)
+	FindAllDepreciationPolicies(ctx context.Context, db DBTX, includeDeleted bool, asOf time.Time) ([]models.DepreciationPolicy, error)
+	LockHasPostedEntries(ctx context.Context, db DBTX, itemId int) (bool, error)
+	ResetBasis(ctx context.Context, db DBTX, investment *models.ItemInvestment) error
 }
 
-type itemInvestmentRepository struct {
-	DB *sql.DB
+// depreciationPolicyColumns selects the effective depreciation setup of an
+// item (alias i) joined to its category (alias c), falling back to defaults.
+const depreciationPolicyColumns = `i.id, i.price, i.purchase_date, COALESCE(i.depreciated_rate, 0),
+	COALESCE(i.depreciation_method, c.depreciation_method, 'straight_line'),
+	COALESCE(i.useful_life_years, c.useful_life_years, 5),
+	COALESCE(i.salvage_value, c.salvage_value, 0)`
+
+// maintenanceCostColumn sums the maintenance spent on an item (alias i)
+const maintenanceCostColumn = `(SELECT COALESCE(SUM(m.cost), 0) FROM maintenance_records m WHERE m.item_id = i.id)`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanDepreciationPolicy(row rowScanner, policy *models.DepreciationPolicy) error {
+	return row.Scan(&policy.ItemID, &policy.Cost, &policy.PurchaseDate, &policy.Rate, &policy.Method, &policy.UsefulLifeYears, &policy.SalvageValue)
 }
 
-func NewItemInvestmentRepository(db *sql.DB) ItemInvestmentRepository {
-	return &itemInvestmentRepository{DB: db}
+type itemInvestmentRepository struct{}
+
+func NewItemInvestmentRepository() ItemInvestmentRepository {
+	return &itemInvestmentRepository{}
 }
 
-// FindAll implements ItemInvestmentRepository.
-func (i *itemInvestmentRepository) CountAll() (*models.ItemInvestment, error) {
+// Create implements ItemInvestmentRepository.
+func (i *itemInvestmentRepository) Create(ctx context.Context, db DBTX, investment *models.ItemInvestment) error {
+	if investment == nil {
+		return errors.New("investment cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	const dateLayout = "2006-01-02"
+	formattedLastDepreciationDate := investment.LastDepreciationDate.Format(dateLayout)
+
+	sqlStatement := `INSERT INTO item_investments (item_id, initial_price, current_value, last_depreciation_date) VALUES ($1, $2, $3, $4)`
+	_, err := db.ExecContext(ctx, sqlStatement, investment.ItemID, investment.InitialPrice, investment.CurrentValue, formattedLastDepreciationDate)
+	if err != nil {
+		log.Printf("Error inserting item investment: %v", err)
+		return err
+	}
+	return nil
+}
+
+// CountAll implements ItemInvestmentRepository. Disposed items are left out.
+func (i *itemInvestmentRepository) CountAll(ctx context.Context, db DBTX) (*models.ItemInvestment, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var itemInvestment models.ItemInvestment
-	sqlStatement := `SELECT SUM(initial_price) AS total_investment, SUM(current_value) AS depreciated_value FROM item_investments`
-	err := i.DB.QueryRow(sqlStatement).Scan(&itemInvestment.TotalInvestment, &itemInvestment.DepricatedValue)
+	sqlStatement := `SELECT COALESCE(SUM(inv.initial_price), 0) AS total_investment, COALESCE(SUM(inv.current_value), 0) AS depreciated_value,
+				COALESCE(SUM(` + maintenanceCostColumn + `), 0) AS maintenance_cost
+				FROM item_investments inv
+				JOIN items i ON inv.item_id = i.id
+				WHERE i.status = 'active' AND i.lifecycle_state <> 'disposed'`
+	err := db.QueryRowContext(ctx, sqlStatement).Scan(&itemInvestment.TotalInvestment, &itemInvestment.DepricatedValue, &itemInvestment.MaintenanceCost)
 	if err != nil {
 		return nil, err
 	}
+	itemInvestment.TotalCostOfOwnership = itemInvestment.TotalInvestment + itemInvestment.MaintenanceCost
 	return &itemInvestment, nil
 }
 
 // FindByItemId implements ItemInvestmentRepository.
-func (i *itemInvestmentRepository) FindByItemId(itemId int) (models.ItemInvestment, error) {
-	sqlStatement := `SELECT i.id, i.name, i.depreciated_rate, inv.initial_price, inv.current_value FROM item_investments inv
-				JOIN items i ON inv.item_id = i.id WHERE inv.item_id = $1`
+func (i *itemInvestmentRepository) FindByItemId(ctx context.Context, db DBTX, itemId int) (models.ItemInvestment, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT i.id, i.name, i.depreciated_rate, inv.initial_price, inv.current_value, inv.last_depreciation_date,
+				COALESCE(i.depreciation_method, c.depreciation_method, 'straight_line'),
+				COALESCE(i.useful_life_years, c.useful_life_years, 5),
+				COALESCE(i.salvage_value, c.salvage_value, 0),
+				` + maintenanceCostColumn + `,
+				(SELECT d.disposed_on FROM item_disposals d WHERE d.item_id = i.id)
+				FROM item_investments inv
+				JOIN items i ON inv.item_id = i.id
+				JOIN categories c ON i.category_id = c.id
+				WHERE inv.item_id = $1`
 	var itemInvestment models.ItemInvestment
-	err := i.DB.QueryRow(sqlStatement, itemId).Scan(&itemInvestment.ItemID, &itemInvestment.ItemName, &itemInvestment.DepreciationRate, &itemInvestment.InitialPrice, &itemInvestment.CurrentValue)
+	err := db.QueryRowContext(ctx, sqlStatement, itemId).Scan(&itemInvestment.ItemID, &itemInvestment.ItemName, &itemInvestment.DepreciationRate, &itemInvestment.InitialPrice, &itemInvestment.CurrentValue,
+		&itemInvestment.LastDepreciationDate, &itemInvestment.DepreciationMethod, &itemInvestment.UsefulLifeYears, &itemInvestment.SalvageValue,
+		&itemInvestment.MaintenanceCost, &itemInvestment.DisposedOn)
 	if err == sql.ErrNoRows {
 		return itemInvestment, nil
 	} else if err != nil {
 		return itemInvestment, err
 	}
 
+	itemInvestment.TotalCostOfOwnership = itemInvestment.InitialPrice + itemInvestment.MaintenanceCost
 	return itemInvestment, nil
 }
+
+// LockHasPostedEntries implements ItemInvestmentRepository. It locks the
+// investment row of an item, as LockInvestment does for posting runs, and
+// reports whether any depreciation has been posted for the item.
+func (i *itemInvestmentRepository) LockHasPostedEntries(ctx context.Context, db DBTX, itemId int) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT EXISTS (SELECT 1 FROM depreciation_entries e WHERE e.item_id = inv.item_id)
+				FROM item_investments inv WHERE inv.item_id = $1 FOR UPDATE`
+	var posted bool
+	err := db.QueryRowContext(ctx, sqlStatement, itemId).Scan(&posted)
+	if err == sql.ErrNoRows {
+		return false, errors.New("item investment does not exist")
+	} else if err != nil {
+		return false, err
+	}
+	return posted, nil
+}
+
+// ResetBasis implements ItemInvestmentRepository. It replaces the price and
+// value an item's investment was created with.
+func (i *itemInvestmentRepository) ResetBasis(ctx context.Context, db DBTX, investment *models.ItemInvestment) error {
+	if investment == nil {
+		return errors.New("investment cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `UPDATE item_investments SET initial_price = $1, current_value = $2, last_depreciation_date = $3 WHERE item_id = $4`
+	_, err := db.ExecContext(ctx, sqlStatement, investment.InitialPrice, investment.CurrentValue, investment.LastDepreciationDate, investment.ItemID)
+	return err
+}
+
+// FindDepreciationPolicy implements ItemInvestmentRepository.
+func (i *itemInvestmentRepository) FindDepreciationPolicy(ctx context.Context, db DBTX, itemId int) (*models.DepreciationPolicy, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT ` + depreciationPolicyColumns + ` FROM items i
+				JOIN categories c ON i.category_id = c.id
+				WHERE i.id = $1`
+	var policy models.DepreciationPolicy
+	err := scanDepreciationPolicy(db.QueryRowContext(ctx, sqlStatement, itemId), &policy)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("item does not exist")
+	} else if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindAllDepreciationPolicies implements ItemInvestmentRepository. Items
+// disposed of on or before asOf no longer count toward the investment and
+// are left out.
+func (i *itemInvestmentRepository) FindAllDepreciationPolicies(ctx context.Context, db DBTX, includeDeleted bool, asOf time.Time) ([]models.DepreciationPolicy, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT ` + depreciationPolicyColumns + `, c.id, c.name FROM items i
+				JOIN categories c ON i.category_id = c.id
+				WHERE ($1 OR i.status = 'active')
+				AND NOT EXISTS (SELECT 1 FROM item_disposals d WHERE d.item_id = i.id AND d.disposed_on <= $2)
+				ORDER BY i.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, includeDeleted, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []models.DepreciationPolicy
+	for rows.Next() {
+		var policy models.DepreciationPolicy
+		err := rows.Scan(&policy.ItemID, &policy.Cost, &policy.PurchaseDate, &policy.Rate, &policy.Method, &policy.UsefulLifeYears, &policy.SalvageValue,
+			&policy.CategoryID, &policy.CategoryName)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+type ItemMovementRepository interface {
+	Create(ctx context.Context, db DBTX, movement *models.ItemMovement) error
+	FindByItemID(ctx context.Context, db DBTX, itemID int) ([]models.ItemMovement, error)
+}
+
+type itemMovementRepository struct{}
+
+func NewItemMovementRepository() ItemMovementRepository {
+	return &itemMovementRepository{}
+}
+
+// Create implements ItemMovementRepository.
+func (m *itemMovementRepository) Create(ctx context.Context, db DBTX, movement *models.ItemMovement) error {
+	if movement == nil {
+		return errors.New("movement cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO item_movements (item_id, from_location_id, to_location_id, moved_by, reason)
+				VALUES ($1, NULLIF($2, 0), $3, NULLIF($4, 0), $5) RETURNING id, moved_at`
+	err := db.QueryRowContext(ctx, sqlStatement, movement.ItemID, movement.FromLocationID, movement.ToLocationID, movement.MovedBy, movement.Reason).
+		Scan(&movement.ID, &movement.MovedAt)
+	if err != nil {
+		log.Printf("Error inserting item movement: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FindByItemID implements ItemMovementRepository. Movements are returned
+// oldest first.
+func (m *itemMovementRepository) FindByItemID(ctx context.Context, db DBTX, itemID int) ([]models.ItemMovement, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ` + locationPathsCTE + `
+				SELECT m.id, m.item_id, COALESCE(m.from_location_id, 0), COALESCE(fp.path, ''), m.to_location_id, tp.path,
+				COALESCE(m.moved_by, 0), COALESCE(u.username, ''), m.reason, m.moved_at
+				FROM item_movements m
+				LEFT JOIN location_paths fp ON fp.id = m.from_location_id
+				JOIN location_paths tp ON tp.id = m.to_location_id
+				LEFT JOIN users u ON u.id = m.moved_by
+				WHERE m.item_id = $1
+				ORDER BY m.moved_at, m.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movements := []models.ItemMovement{}
+	for rows.Next() {
+		var movement models.ItemMovement
+		err := rows.Scan(&movement.ID, &movement.ItemID, &movement.FromLocationID, &movement.FromLocationPath, &movement.ToLocationID, &movement.ToLocationPath,
+			&movement.MovedBy, &movement.MovedByUsername, &movement.Reason, &movement.MovedAt)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+	return movements, rows.Err()
+}
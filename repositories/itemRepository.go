@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -10,107 +11,88 @@ import (
 	"time"
 
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/lib/pq"
 )
 
-type ItemRepository interface {
-	FindAll() ([]models.Item, error)
-	FindByID(id int) (*models.Item, error)
-	Create(itemInput *models.Item) (*models.Item, error)
-	Update(itemInput *models.Item) (*models.Item, error)
-	Delete(id int) (string, error)
-	ReplaceReminder(threshold int) ([]models.Item, error)
-	CreateItemInvestment(item *models.Item) error
-}
+// ErrDuplicateSerialNumber is returned when another item has the serial number
+var ErrDuplicateSerialNumber = errors.New("another item already has this serial number")
+
+// uniqueViolation is the PostgreSQL error code raised by a UNIQUE constraint
+const uniqueViolation = "23505"
 
-type itemRepository struct {
-	DB *sql.DB
+// isDuplicateSerialNumber reports whether err comes from the unique serial number constraint
+func isDuplicateSerialNumber(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "items_serial_number_key"
 }
 
-func NewItemRepository(db *sql.DB) ItemRepository {
-	return &itemRepository{DB: db}
+type ItemRepository interface {
+	FindAll(ctx context.Context, db DBTX, filter models.ItemFilter) ([]models.Item, error)
+	FindByID(ctx context.Context, db DBTX, id int) (*models.Item, error)
+	Create(ctx context.Context, db DBTX, itemInput *models.Item) (*models.Item, error)
+	Update(ctx context.Context, db DBTX, itemInput *models.Item) (*models.Item, error)
+	Delete(ctx context.Context, db DBTX, id int) error
+	FindReplacementCandidates(ctx context.Context, db DBTX, onlyFlagged bool, limit, offset int) ([]models.ReplacementCandidate, int, error)
+	UpdateReplacementFlag(ctx context.Context, db DBTX, id int, isReplacementNeeded bool) (bool, error)
+	Restore(ctx context.Context, db DBTX, id int) (*models.Item, error)
+	LockLocation(ctx context.Context, db DBTX, id int) (int, error)
+	Lock(ctx context.Context, db DBTX, id int) error
+	PurgeDeleted(ctx context.Context, db DBTX, cutoff time.Time) (int, []string, error)
+	LockLifecycle(ctx context.Context, db DBTX, id int) (models.LifecycleState, error)
+	UpdateLifecycleState(ctx context.Context, db DBTX, id int, state models.LifecycleState) error
+	FindWarrantyExpiring(ctx context.Context, db DBTX, withinDays int, onlyUnalerted bool) ([]models.WarrantyExpiry, error)
+	MarkWarrantyAlerted(ctx context.Context, db DBTX, id int, endsOn time.Time) error
+	NextAssetTag(ctx context.Context, db DBTX, categoryID int) (string, error)
+	FindLabels(ctx context.Context, db DBTX, ids []int) ([]models.Item, error)
+	FindIDByIdentifier(ctx context.Context, db DBTX, assetTag, serialNumber string) (int, error)
 }
 
-// CreateItemInvestment implements ItemRepository.
-func (i *itemRepository) CreateItemInvestment(item *models.Item) error {
-	if item == nil {
-		return errors.New("item cannot be nil")
-	}
+type itemRepository struct{}
 
-	const dateLayout = "2006-01-02"
-	lastDepreciationDate := time.Now()
-	formattedLastDepreciationDate := lastDepreciationDate.Format(dateLayout)
-	currentValue := item.Price - (item.Price * (float64(item.DepreciatedRate) / 100.0))
+// usageDaysColumn computes how long an item (alias i) has been in use, so the
+// value is always current instead of being stored by a write on every read.
+const usageDaysColumn = `COALESCE(GREATEST(CURRENT_DATE - i.purchase_date, 0), 0)`
 
-	tx, err := i.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err)
-		return err
-	}
+// identityColumns selects the serial number, manufacturer and model of an item (alias i)
+const identityColumns = `COALESCE(i.serial_number, ''), COALESCE(i.manufacturer, ''), COALESCE(i.model, '')`
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // Re-panic after rollback
-		} else if err != nil {
-			log.Printf("Rolling back transaction due to error: %v", err)
-			tx.Rollback()
-		}
-	}()
+// warrantyColumns selects the warranty and insurance details of an item (alias i)
+const warrantyColumns = `COALESCE(i.warranty_provider, ''), i.warranty_ends_on, COALESCE(i.warranty_coverage, ''), i.insurance_policy_refs`
 
-	sqlStatement := `INSERT INTO item_investments (item_id, initial_price, current_value, last_depreciation_date) VALUES ($1, $2, $3, $4)`
-	_, err = tx.Exec(sqlStatement, item.ID, item.Price, currentValue, formattedLastDepreciationDate)
-	if err != nil {
-		log.Printf("Error inserting item investment: %v", err)
-		tx.Rollback()
-		return err
-	}
-
-	if err = tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err)
-		return err
-	}
-	return nil
+func NewItemRepository() ItemRepository {
+	return &itemRepository{}
 }
 
-func (i *itemRepository) Create(itemInput *models.Item) (*models.Item, error) {
+func (i *itemRepository) Create(ctx context.Context, db DBTX, itemInput *models.Item) (*models.Item, error) {
 	if itemInput == nil {
 		return nil, fmt.Errorf("itemInput cannot be nil")
 	}
 
-	tx, err := i.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err)
-		return nil, err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // Re-panic after rollback
-		} else if err != nil {
-			log.Printf("Rolling back transaction due to error: %v", err)
-			tx.Rollback()
-		}
-	}()
-
-	sqlStatement := `INSERT INTO items (name, category_id, photo_url, price, purchase_date, depreciated_rate) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
-	err = tx.QueryRow(sqlStatement, itemInput.Name, itemInput.CategoryID, itemInput.PhotoURL, itemInput.Price, itemInput.PurchaseDate, itemInput.DepreciatedRate).Scan(&itemInput.ID)
-	if err != nil {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	insurancePolicyRefs := itemInput.InsurancePolicyRefs
+	if insurancePolicyRefs == nil {
+		insurancePolicyRefs = []string{}
+	}
+	sqlStatement := `INSERT INTO items (name, asset_tag, category_id, photo_url, price, purchase_date, depreciated_rate, depreciation_method, useful_life_years, salvage_value,
+				replacement_after_days, replacement_min_book_value, location_id, warranty_provider, warranty_ends_on, warranty_coverage, insurance_policy_refs, lifecycle_state,
+				serial_number, manufacturer, model)
+				VALUES ($1, $18, $2, $3, $4, $5, $6, NULLIF($7, '')::depreciation_method_enum, NULLIF($8, 0), NULLIF($9, 0), NULLIF($10, 0), NULLIF($11, 0), NULLIF($12, 0),
+				NULLIF($13, ''), $14, NULLIF($15, ''), $16, COALESCE(NULLIF($17, ''), 'in_stock')::lifecycle_state_enum,
+				NULLIF($19, ''), NULLIF($20, ''), NULLIF($21, '')) RETURNING id`
+	err := db.QueryRowContext(ctx, sqlStatement, itemInput.Name, itemInput.CategoryID, itemInput.PhotoURL, itemInput.Price, itemInput.PurchaseDate, itemInput.DepreciatedRate,
+		itemInput.DepreciationMethod, itemInput.UsefulLifeYears, itemInput.SalvageValue, itemInput.ReplacementAfterDays, itemInput.ReplacementMinBookValue, itemInput.LocationID,
+		itemInput.WarrantyProvider, itemInput.WarrantyEndsOn, itemInput.WarrantyCoverage, pq.Array(insurancePolicyRefs), itemInput.LifecycleState,
+		itemInput.AssetTag, itemInput.SerialNumber, itemInput.Manufacturer, itemInput.Model).Scan(&itemInput.ID)
+	if isDuplicateSerialNumber(err) {
+		return nil, ErrDuplicateSerialNumber
+	} else if err != nil {
 		log.Printf("Error inserting item: %v", err)
 		return nil, err
 	}
 
-	if err = i.CreateItemInvestment(itemInput); err != nil {
-		log.Printf("Error creating item investment: %v", err)
-		return nil, err
-	}
-
-	if err = tx.Commit(); err != nil {
-		log.Printf("Error committing transaction: %v", err)
-		return nil, err
-	}
-
-	item, err := i.FindByID(itemInput.ID)
+	item, err := i.FindByID(ctx, db, itemInput.ID)
 	if err != nil {
 		log.Printf("Error finding item by ID after insert: %v", err)
 		return nil, err
@@ -118,43 +100,59 @@ func (i *itemRepository) Create(itemInput *models.Item) (*models.Item, error) {
 	return item, nil
 }
 
-// Delete implements ItemRepository.
-func (i *itemRepository) Delete(id int) (string, error) {
-	tx, err := i.DB.Begin()
+// Delete implements ItemRepository. The photo is kept so the item can be
+// restored; it is removed when the item is purged.
+func (i *itemRepository) Delete(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `UPDATE items SET status = 'deleted', deleted_at = NOW() WHERE id = $1 AND status = 'active'`
+	result, err := db.ExecContext(ctx, sqlStatement, id)
 	if err != nil {
-		log.Printf("Error starting transaction: %v", err.Error())
-		return "", err
+		return err
 	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // Re-panic after rollback
-		} else if err != nil {
-			log.Printf("Rolling back transaction due to error: %v", err.Error())
-			tx.Rollback()
-		}
-	}()
-
-	var photoUrl string
-	sqlStatement := `UPDATE items SET status = 'deleted' WHERE id = $1 RETURNING photo_url`
-	err = tx.QueryRow(sqlStatement, id).Scan(&photoUrl)
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return "", err
+		return err
 	}
-
-	if err := tx.Commit(); err != nil {
-		return "", err
+	if rowsAffected == 0 {
+		return errors.New("item does not exist")
 	}
-	return photoUrl, err
+	return nil
 }
 
 // FindAll implements ItemRepository.
-func (i *itemRepository) FindAll() ([]models.Item, error) {
-	sqlStatement := `SELECT i.id, i.name, c.name, i.photo_url, i.price, i.purchase_date, i.total_usage_days, i.is_replacement_needed, i.depreciated_rate FROM items i 
-				JOIN categories c ON i.category_id = c.id 
-				WHERE i.status = 'active'`
-	rows, err := i.DB.Query(sqlStatement)
+func (i *itemRepository) FindAll(ctx context.Context, db DBTX, filter models.ItemFilter) ([]models.Item, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	// category_scope holds category $1, plus its subcategories when $2 is
+	// set; location_scope holds location $4 and all its sublocations
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `, ` + locationPathsCTE + `,
+				category_scope AS (
+					SELECT id FROM categories WHERE id = $1
+					UNION
+					SELECT c.id FROM categories c JOIN category_scope s ON c.parent_id = s.id WHERE $2
+				),
+				location_scope AS (
+					SELECT id FROM locations WHERE id = $4
+					UNION
+					SELECT l.id FROM locations l JOIN location_scope s ON l.parent_id = s.id WHERE l.status = 'active'
+				)
+				SELECT i.id, i.name, i.asset_tag, ` + identityColumns + `, i.category_id, c.name, cp.path, COALESCE(i.location_id, 0), COALESCE(lp.path, ''), i.photo_url, i.price, i.purchase_date, ` + usageDaysColumn + `,
+				i.is_replacement_needed, i.lifecycle_state, i.depreciated_rate,
+				COALESCE(i.depreciation_method::text, ''), COALESCE(i.useful_life_years, 0), COALESCE(i.salvage_value, 0),
+				COALESCE(i.replacement_after_days, 0), COALESCE(i.replacement_min_book_value, 0), ` + warrantyColumns + `, i.deleted_at FROM items i
+				JOIN categories c ON i.category_id = c.id
+				JOIN category_paths cp ON cp.id = c.id
+				LEFT JOIN location_paths lp ON lp.id = i.location_id
+				WHERE i.status = COALESCE(NULLIF($3, ''), 'active')::status_enum
+				AND ($1 = 0 OR i.category_id IN (SELECT id FROM category_scope))
+				AND ($4 = 0 OR i.location_id IN (SELECT id FROM location_scope))
+				AND ($5 = '' OR i.lifecycle_state = $5::lifecycle_state_enum)
+				AND ($6 = '' OR i.asset_tag ILIKE '%' || $6 || '%' OR i.name ILIKE '%' || $6 || '%' OR i.serial_number ILIKE '%' || $6 || '%')
+				ORDER BY i.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, filter.CategoryID, filter.IncludeDescendants, filter.Status, filter.LocationID, filter.State, filter.Search)
 	if err != nil {
 		return nil, err
 	}
@@ -163,23 +161,35 @@ func (i *itemRepository) FindAll() ([]models.Item, error) {
 	var items []models.Item
 	for rows.Next() {
 		var item models.Item
-		err = rows.Scan(&item.ID, &item.Name, &item.CategoryName, &item.PhotoURL, &item.Price, &item.PurchaseDate, &item.TotalUsageDays, &item.IsReplacementNeeded, &item.DepreciatedRate)
+		err = rows.Scan(&item.ID, &item.Name, &item.AssetTag, &item.SerialNumber, &item.Manufacturer, &item.Model, &item.CategoryID, &item.CategoryName, &item.CategoryPath, &item.LocationID, &item.LocationPath, &item.PhotoURL, &item.Price, &item.PurchaseDate, &item.TotalUsageDays, &item.IsReplacementNeeded, &item.LifecycleState, &item.DepreciatedRate,
+			&item.DepreciationMethod, &item.UsefulLifeYears, &item.SalvageValue, &item.ReplacementAfterDays, &item.ReplacementMinBookValue,
+			&item.WarrantyProvider, &item.WarrantyEndsOn, &item.WarrantyCoverage, pq.Array(&item.InsurancePolicyRefs), &item.DeletedAt)
 		if err != nil {
 			return nil, err
 		}
 		items = append(items, item)
 	}
 
-	return items, nil
+	return items, rows.Err()
 }
 
 // FindByID implements ItemRepository.
-func (i *itemRepository) FindByID(id int) (*models.Item, error) {
+func (i *itemRepository) FindByID(ctx context.Context, db DBTX, id int) (*models.Item, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var item models.Item
-	sqlStatement := `SELECT i.id, i.name, c.name, i.photo_url, i.price, i.purchase_date, i.total_usage_days FROM items i 
-					JOIN categories c ON i.category_id = c.id 
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `, ` + locationPathsCTE + `
+					SELECT i.id, i.name, i.asset_tag, ` + identityColumns + `, i.category_id, c.name, cp.path, COALESCE(i.location_id, 0), COALESCE(lp.path, ''), i.photo_url, i.price, i.purchase_date, ` + usageDaysColumn + `, i.lifecycle_state, i.depreciated_rate,
+					COALESCE(i.depreciation_method::text, ''), COALESCE(i.useful_life_years, 0), COALESCE(i.salvage_value, 0),
+					COALESCE(i.replacement_after_days, 0), COALESCE(i.replacement_min_book_value, 0), ` + warrantyColumns + ` FROM items i
+					JOIN categories c ON i.category_id = c.id
+					JOIN category_paths cp ON cp.id = c.id
+					LEFT JOIN location_paths lp ON lp.id = i.location_id
 					WHERE i.id = $1 AND i.status = 'active'`
-	err := i.DB.QueryRow(sqlStatement, id).Scan(&item.ID, &item.Name, &item.CategoryName, &item.PhotoURL, &item.Price, &item.PurchaseDate, &item.TotalUsageDays)
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&item.ID, &item.Name, &item.AssetTag, &item.SerialNumber, &item.Manufacturer, &item.Model, &item.CategoryID, &item.CategoryName, &item.CategoryPath, &item.LocationID, &item.LocationPath, &item.PhotoURL, &item.Price, &item.PurchaseDate, &item.TotalUsageDays, &item.LifecycleState, &item.DepreciatedRate,
+		&item.DepreciationMethod, &item.UsefulLifeYears, &item.SalvageValue, &item.ReplacementAfterDays, &item.ReplacementMinBookValue,
+		&item.WarrantyProvider, &item.WarrantyEndsOn, &item.WarrantyCoverage, pq.Array(&item.InsurancePolicyRefs))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -189,22 +199,9 @@ func (i *itemRepository) FindByID(id int) (*models.Item, error) {
 }
 
 // Update implements ItemRepository.
-func (i *itemRepository) Update(itemInput *models.Item) (*models.Item, error) {
-	tx, err := i.DB.Begin()
-	if err != nil {
-		log.Printf("Error starting transaction: %v", err.Error())
-		return nil, err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // Re-panic after rollback
-		} else if err != nil {
-			log.Printf("Rolling back transaction due to error: %v", err.Error())
-			tx.Rollback()
-		}
-	}()
+func (i *itemRepository) Update(ctx context.Context, db DBTX, itemInput *models.Item) (*models.Item, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 
 	fields := make(map[string]interface{})
 
@@ -214,6 +211,9 @@ func (i *itemRepository) Update(itemInput *models.Item) (*models.Item, error) {
 	if itemInput.CategoryID != 0 {
 		fields["category_id"] = itemInput.CategoryID
 	}
+	if itemInput.LocationID != 0 {
+		fields["location_id"] = itemInput.LocationID
+	}
 	if itemInput.PhotoURL != "" {
 		fields["photo_url"] = itemInput.PhotoURL
 	}
@@ -223,12 +223,45 @@ func (i *itemRepository) Update(itemInput *models.Item) (*models.Item, error) {
 	if !itemInput.PurchaseDate.IsZero() {
 		fields["purchase_date"] = itemInput.PurchaseDate
 	}
-	if itemInput.TotalUsageDays != 0 {
-		fields["total_usage_days"] = itemInput.TotalUsageDays
-	}
 	if itemInput.DepreciatedRate != 0 {
 		fields["depreciated_rate"] = itemInput.DepreciatedRate
 	}
+	if itemInput.DepreciationMethod != "" {
+		fields["depreciation_method"] = itemInput.DepreciationMethod
+	}
+	if itemInput.UsefulLifeYears != 0 {
+		fields["useful_life_years"] = itemInput.UsefulLifeYears
+	}
+	if itemInput.SalvageValue != 0 {
+		fields["salvage_value"] = itemInput.SalvageValue
+	}
+	if itemInput.ReplacementAfterDays != 0 {
+		fields["replacement_after_days"] = itemInput.ReplacementAfterDays
+	}
+	if itemInput.ReplacementMinBookValue != 0 {
+		fields["replacement_min_book_value"] = itemInput.ReplacementMinBookValue
+	}
+	if itemInput.WarrantyProvider != "" {
+		fields["warranty_provider"] = itemInput.WarrantyProvider
+	}
+	if itemInput.WarrantyEndsOn != nil {
+		fields["warranty_ends_on"] = *itemInput.WarrantyEndsOn
+	}
+	if itemInput.WarrantyCoverage != "" {
+		fields["warranty_coverage"] = itemInput.WarrantyCoverage
+	}
+	if itemInput.InsurancePolicyRefs != nil {
+		fields["insurance_policy_refs"] = pq.Array(itemInput.InsurancePolicyRefs)
+	}
+	if itemInput.SerialNumber != "" {
+		fields["serial_number"] = itemInput.SerialNumber
+	}
+	if itemInput.Manufacturer != "" {
+		fields["manufacturer"] = itemInput.Manufacturer
+	}
+	if itemInput.Model != "" {
+		fields["model"] = itemInput.Model
+	}
 
 	fields["updated_at"] = time.Now()
 
@@ -249,68 +282,337 @@ func (i *itemRepository) Update(itemInput *models.Item) (*models.Item, error) {
 	values = append(values, itemInput.ID)
 
 	var id int
-	err = tx.QueryRow(sqlStatement, values...).Scan(&id)
+	err := db.QueryRowContext(ctx, sqlStatement, values...).Scan(&id)
 	if err == sql.ErrNoRows {
 		return nil, nil
+	} else if isDuplicateSerialNumber(err) {
+		return nil, ErrDuplicateSerialNumber
 	} else if err != nil {
 		return nil, err
 	}
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
 
-	updatedItem, err := i.FindByID(id)
+	updatedItem, err := i.FindByID(ctx, db, id)
 	if err != nil {
 		return nil, err
 	}
 	return updatedItem, nil
 }
 
-// ReplaceReminder implements ItemRepository.
-func (i *itemRepository) ReplaceReminder(threshold int) ([]models.Item, error) {
-	tx, err := i.DB.Begin()
+// FindReplacementCandidates implements ItemRepository. With onlyFlagged it
+// returns just the items currently flagged for replacement. A limit of zero
+// returns every matching item. The total number of matches is returned too.
+// Retired and disposed items are never candidates.
+func (i *itemRepository) FindReplacementCandidates(ctx context.Context, db DBTX, onlyFlagged bool, limit, offset int) ([]models.ReplacementCandidate, int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `
+				SELECT i.name, i.category_id, c.name, cp.path, i.photo_url, ` + usageDaysColumn + `, i.is_replacement_needed, ` + depreciationPolicyColumns + `,
+				COALESCE(i.replacement_after_days, c.replacement_after_days, 100),
+				COALESCE(i.replacement_min_book_value, c.replacement_min_book_value, 0),
+				CASE WHEN i.replacement_after_days IS NOT NULL OR i.replacement_min_book_value IS NOT NULL THEN 'item' ELSE 'category' END,
+				COUNT(*) OVER ()
+				FROM items i
+				JOIN categories c ON i.category_id = c.id
+				JOIN category_paths cp ON cp.id = c.id
+				WHERE i.status = 'active' AND i.lifecycle_state NOT IN ('retired', 'disposed')
+				AND (NOT $1 OR i.is_replacement_needed)
+				ORDER BY i.id
+				LIMIT NULLIF($2, 0) OFFSET $3`
+	rows, err := db.QueryContext(ctx, sqlStatement, onlyFlagged, limit, offset)
 	if err != nil {
-		log.Printf("Error starting transaction: %v", err.Error())
-		return nil, err
+		return nil, 0, err
 	}
+	defer rows.Close()
 
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // Re-panic after rollback
-		} else if err != nil {
-			log.Printf("Rolling back transaction due to error: %v", err.Error())
-			tx.Rollback()
+	var candidates []models.ReplacementCandidate
+	total := 0
+	for rows.Next() {
+		var candidate models.ReplacementCandidate
+		item, policy := &candidate.Item, &candidate.Depreciation
+		err = rows.Scan(&item.Name, &item.CategoryID, &item.CategoryName, &item.CategoryPath, &item.PhotoURL, &item.TotalUsageDays, &item.IsReplacementNeeded,
+			&policy.ItemID, &policy.Cost, &policy.PurchaseDate, &policy.Rate, &policy.Method, &policy.UsefulLifeYears, &policy.SalvageValue,
+			&candidate.Policy.AfterDays, &candidate.Policy.MinBookValue, &candidate.Policy.Source, &total)
+		if err != nil {
+			return nil, 0, err
 		}
-	}()
+		item.ID = policy.ItemID
+		item.Price = policy.Cost
+		item.PurchaseDate = policy.PurchaseDate
+		item.DepreciatedRate = int(policy.Rate)
+		candidates = append(candidates, candidate)
+	}
+	return candidates, total, rows.Err()
+}
 
-	items, err := i.FindAll()
+// UpdateReplacementFlag implements ItemRepository. It only writes when the
+// flag actually changes and reports whether it did.
+func (i *itemRepository) UpdateReplacementFlag(ctx context.Context, db DBTX, id int, isReplacementNeeded bool) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	updateStatement := `UPDATE items SET is_replacement_needed = $1 WHERE id = $2 AND is_replacement_needed IS DISTINCT FROM $1`
+	result, err := db.ExecContext(ctx, updateStatement, isReplacementNeeded, id)
 	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// Restore implements ItemRepository. An item whose category is deleted
+// cannot be restored until the category is.
+func (i *itemRepository) Restore(ctx context.Context, db DBTX, id int) (*models.Item, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	// Lock the category too, so it cannot be deleted while the item comes back
+	var categoryStatus string
+	sqlStatement := `SELECT c.status FROM items i
+				JOIN categories c ON i.category_id = c.id
+				WHERE i.id = $1 AND i.status = 'deleted'
+				FOR UPDATE`
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&categoryStatus)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("deleted item does not exist")
+	} else if err != nil {
 		return nil, err
 	}
+	if categoryStatus != models.StatusActive {
+		return nil, errors.New("item category is deleted; restore the category first")
+	}
 
-	var needReplaceItems []models.Item
-	currentDate := time.Now()
-	for _, item := range items {
-		if !item.PurchaseDate.IsZero() {
-			daysSincePurchase := int(currentDate.Sub(item.PurchaseDate).Hours() / 24)
-			item.TotalUsageDays = daysSincePurchase
-		}
+	updateStatement := `UPDATE items SET status = 'active', deleted_at = NULL, updated_at = NOW() WHERE id = $1`
+	if _, err := db.ExecContext(ctx, updateStatement, id); err != nil {
+		return nil, err
+	}
+	return i.FindByID(ctx, db, id)
+}
 
-		if item.TotalUsageDays < threshold && item.IsReplacementNeeded {
-			item.IsReplacementNeeded = false
-		} else if item.TotalUsageDays > threshold {
-			item.IsReplacementNeeded = true
+// PurgeDeleted implements ItemRepository. It permanently removes the items
+// deleted before cutoff, with their investment and depreciation rows, and
+// returns how many were removed along with the photo paths that no remaining
+// item refers to.
+func (i *itemRepository) PurgeDeleted(ctx context.Context, db DBTX, cutoff time.Time) (int, []string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	// The outer query still sees the purged rows, so they are excluded by id
+	sqlStatement := `WITH purged AS (
+					DELETE FROM items WHERE status = 'deleted' AND COALESCE(deleted_at, updated_at) < $1
+					RETURNING id, COALESCE(photo_url, '') AS photo_url
+				)
+				SELECT p.photo_url, p.photo_url <> '' AND NOT EXISTS (
+					SELECT 1 FROM items i WHERE i.photo_url = p.photo_url AND i.id NOT IN (SELECT id FROM purged)
+				) FROM purged p`
+	rows, err := db.QueryContext(ctx, sqlStatement, cutoff)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	purged := 0
+	photoURLs := []string{}
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var photoURL string
+		var unreferenced bool
+		if err := rows.Scan(&photoURL, &unreferenced); err != nil {
+			return 0, nil, err
+		}
+		purged++
+		if unreferenced && !seen[photoURL] {
+			seen[photoURL] = true
+			photoURLs = append(photoURLs, photoURL)
 		}
-		updateStatement := `UPDATE items SET total_usage_days = $1, is_replacement_needed = $2 WHERE id = $3`
-		_, err = tx.Exec(updateStatement, item.TotalUsageDays, item.IsReplacementNeeded, item.ID)
+	}
+	return purged, photoURLs, rows.Err()
+}
+
+// LockLocation implements ItemRepository. It locks an active item until the
+// surrounding transaction ends and returns its current location, or zero.
+func (i *itemRepository) LockLocation(ctx context.Context, db DBTX, id int) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var locationID int
+	sqlStatement := `SELECT COALESCE(location_id, 0) FROM items WHERE id = $1 AND status = 'active' FOR UPDATE`
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&locationID)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("item does not exist")
+	} else if err != nil {
+		return 0, err
+	}
+	return locationID, nil
+}
+
+// Lock implements ItemRepository. It locks an active item until the
+// surrounding transaction ends, serializing changes such as check-outs.
+func (i *itemRepository) Lock(ctx context.Context, db DBTX, id int) error {
+	_, err := i.LockLocation(ctx, db, id)
+	return err
+}
+
+// LockLifecycle implements ItemRepository. It locks an active item until the
+// surrounding transaction ends and returns its lifecycle state.
+func (i *itemRepository) LockLifecycle(ctx context.Context, db DBTX, id int) (models.LifecycleState, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var state models.LifecycleState
+	sqlStatement := `SELECT lifecycle_state FROM items WHERE id = $1 AND status = 'active' FOR UPDATE`
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&state)
+	if err == sql.ErrNoRows {
+		return "", errors.New("item does not exist")
+	} else if err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+// UpdateLifecycleState implements ItemRepository.
+func (i *itemRepository) UpdateLifecycleState(ctx context.Context, db DBTX, id int, state models.LifecycleState) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `UPDATE items SET lifecycle_state = $2, updated_at = NOW() WHERE id = $1 AND status = 'active'`
+	result, err := db.ExecContext(ctx, sqlStatement, id, state)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("item does not exist")
+	}
+	return nil
+}
+
+// FindWarrantyExpiring implements ItemRepository. It returns the active items,
+// other than retired or disposed ones, whose warranty runs out between today and withinDays from now, soonest
+// first. With onlyUnalerted it skips warranties already alerted for.
+func (i *itemRepository) FindWarrantyExpiring(ctx context.Context, db DBTX, withinDays int, onlyUnalerted bool) ([]models.WarrantyExpiry, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `, ` + locationPathsCTE + `
+				SELECT i.id, i.name, cp.path, COALESCE(lp.path, ''), COALESCE(i.warranty_provider, ''), i.warranty_ends_on, COALESCE(i.warranty_coverage, ''),
+				i.insurance_policy_refs, i.warranty_ends_on - CURRENT_DATE
+				FROM items i
+				JOIN category_paths cp ON cp.id = i.category_id
+				LEFT JOIN location_paths lp ON lp.id = i.location_id
+				WHERE i.status = 'active' AND i.lifecycle_state NOT IN ('retired', 'disposed') AND i.warranty_ends_on BETWEEN CURRENT_DATE AND CURRENT_DATE + $1::int
+				AND (NOT $2 OR i.warranty_alerted_for IS DISTINCT FROM i.warranty_ends_on)
+				ORDER BY i.warranty_ends_on, i.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, withinDays, onlyUnalerted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expiries := []models.WarrantyExpiry{}
+	for rows.Next() {
+		var expiry models.WarrantyExpiry
+		err := rows.Scan(&expiry.ItemID, &expiry.ItemName, &expiry.CategoryPath, &expiry.LocationPath, &expiry.WarrantyProvider, &expiry.WarrantyEndsOn,
+			&expiry.WarrantyCoverage, pq.Array(&expiry.InsurancePolicyRefs), &expiry.DaysLeft)
 		if err != nil {
 			return nil, err
 		}
-		needReplaceItems = append(needReplaceItems, item)
+		expiries = append(expiries, expiry)
+	}
+	return expiries, rows.Err()
+}
+
+// MarkWarrantyAlerted implements ItemRepository. It records that an alert
+// went out for the warranty ending on endsOn, so a later change of the end
+// date is alerted again.
+func (i *itemRepository) MarkWarrantyAlerted(ctx context.Context, db DBTX, id int, endsOn time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `UPDATE items SET warranty_alerted_for = $2 WHERE id = $1`
+	_, err := db.ExecContext(ctx, sqlStatement, id, endsOn)
+	return err
+}
+
+// NextAssetTag implements ItemRepository. It hands out the next tag for an
+// item of the category, such as IT-2026-000123, using the tag prefix of the
+// category or its closest ancestor that has one. Numbers restart every year.
+func (i *itemRepository) NextAssetTag(ctx context.Context, db DBTX, categoryID int) (string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ancestors AS (
+					SELECT id, parent_id, tag_prefix, 0 AS depth FROM categories WHERE id = $1
+					UNION ALL
+					SELECT c.id, c.parent_id, c.tag_prefix, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+				)
+				INSERT INTO asset_tag_sequences (prefix, year, last_value)
+				SELECT COALESCE((SELECT tag_prefix FROM ancestors WHERE tag_prefix IS NOT NULL ORDER BY depth LIMIT 1), $2),
+				EXTRACT(YEAR FROM CURRENT_DATE)::int, 1
+				ON CONFLICT (prefix, year) DO UPDATE SET last_value = asset_tag_sequences.last_value + 1
+				RETURNING prefix, year, last_value`
+	var prefix string
+	var year, number int
+	err := db.QueryRowContext(ctx, sqlStatement, categoryID, models.DefaultAssetTagPrefix).Scan(&prefix, &year, &number)
+	if err != nil {
+		log.Printf("Error generating asset tag: %v", err.Error())
+		return "", err
 	}
-	if err := tx.Commit(); err != nil {
+	return fmt.Sprintf("%s-%d-%06d", prefix, year, number), nil
+}
+
+// FindLabels implements ItemRepository. It returns the name and asset tag of
+// the active items among ids, in the order of ids.
+func (i *itemRepository) FindLabels(ctx context.Context, db DBTX, ids []int) ([]models.Item, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var itemIDs pq.Int64Array
+	for _, id := range ids {
+		itemIDs = append(itemIDs, int64(id))
+	}
+	sqlStatement := `SELECT i.id, i.name, i.asset_tag FROM items i
+				JOIN unnest($1::int[]) WITH ORDINALITY AS requested(id, position) ON requested.id = i.id
+				WHERE i.status = 'active'
+				ORDER BY requested.position`
+	rows, err := db.QueryContext(ctx, sqlStatement, itemIDs)
+	if err != nil {
 		return nil, err
 	}
-	return needReplaceItems, nil
+	defer rows.Close()
+
+	items := []models.Item{}
+	for rows.Next() {
+		var item models.Item
+		if err := rows.Scan(&item.ID, &item.Name, &item.AssetTag); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// FindIDByIdentifier implements ItemRepository. It returns the id of the
+// active item with the given asset tag or serial number, whichever is not
+// empty, or 0 when there is none.
+func (i *itemRepository) FindIDByIdentifier(ctx context.Context, db DBTX, assetTag, serialNumber string) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var id int
+	sqlStatement := `SELECT id FROM items
+				WHERE status = 'active' AND (($1 <> '' AND asset_tag = $1) OR ($2 <> '' AND serial_number = $2))`
+	err := db.QueryRowContext(ctx, sqlStatement, assetTag, serialNumber).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return id, nil
 }
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/lib/pq"
+)
+
+// ErrReservationConflict is returned when a booking overlaps another active
+// booking of the same item, as detected by the exclusion constraint.
+var ErrReservationConflict = errors.New("item is already reserved for part of this period")
+
+// exclusionViolation is the PostgreSQL error code raised by an EXCLUDE constraint
+const exclusionViolation = "23P01"
+
+type ItemReservationRepository interface {
+	Create(ctx context.Context, db DBTX, reservation *models.ItemReservation) error
+	Lock(ctx context.Context, db DBTX, id int) (*models.ItemReservation, error)
+	UpdateStatus(ctx context.Context, db DBTX, reservation *models.ItemReservation) error
+	FindOverlapping(ctx context.Context, db DBTX, itemID int, from, to time.Time) ([]models.ItemReservation, error)
+	FindUpcomingByUser(ctx context.Context, db DBTX, userID int) ([]models.ItemReservation, error)
+	FindStarted(ctx context.Context, db DBTX, now time.Time) ([]models.ItemReservation, error)
+}
+
+// reservationColumns selects a reservation (alias r) with its item (alias i)
+// and user (alias u)
+const reservationColumns = `r.id, r.item_id, i.name, r.user_id, u.username, r.starts_at, r.ends_at, COALESCE(r.purpose, ''), r.status,
+	COALESCE(r.assignment_id, 0), r.cancelled_at`
+
+const reservationJoins = ` FROM item_reservations r
+				JOIN items i ON r.item_id = i.id
+				JOIN users u ON r.user_id = u.id`
+
+func scanReservation(row rowScanner, reservation *models.ItemReservation) error {
+	return row.Scan(&reservation.ID, &reservation.ItemID, &reservation.ItemName, &reservation.UserID, &reservation.Username, &reservation.StartsAt, &reservation.EndsAt,
+		&reservation.Purpose, &reservation.Status, &reservation.AssignmentID, &reservation.CancelledAt)
+}
+
+type itemReservationRepository struct{}
+
+func NewItemReservationRepository() ItemReservationRepository {
+	return &itemReservationRepository{}
+}
+
+// Create implements ItemReservationRepository.
+func (r *itemReservationRepository) Create(ctx context.Context, db DBTX, reservation *models.ItemReservation) error {
+	if reservation == nil {
+		return errors.New("reservation cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO item_reservations (item_id, user_id, starts_at, ends_at, purpose)
+				VALUES ($1, $2, $3, $4, NULLIF($5, '')) RETURNING id, status`
+	err := db.QueryRowContext(ctx, sqlStatement, reservation.ItemID, reservation.UserID, reservation.StartsAt, reservation.EndsAt, reservation.Purpose).
+		Scan(&reservation.ID, &reservation.Status)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == exclusionViolation {
+		return ErrReservationConflict
+	} else if err != nil {
+		log.Printf("Error inserting item reservation: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Lock implements ItemReservationRepository. It locks a reservation until
+// the surrounding transaction ends.
+func (r *itemReservationRepository) Lock(ctx context.Context, db DBTX, id int) (*models.ItemReservation, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var reservation models.ItemReservation
+	sqlStatement := `SELECT ` + reservationColumns + reservationJoins + ` WHERE r.id = $1 FOR UPDATE OF r`
+	err := scanReservation(db.QueryRowContext(ctx, sqlStatement, id), &reservation)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("reservation does not exist")
+	} else if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// UpdateStatus implements ItemReservationRepository. It saves the status and
+// assignment of a reservation, stamping cancelled_at when it is cancelled.
+func (r *itemReservationRepository) UpdateStatus(ctx context.Context, db DBTX, reservation *models.ItemReservation) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `UPDATE item_reservations SET status = $2, assignment_id = NULLIF($3, 0),
+				cancelled_at = CASE WHEN $2 = 'cancelled' THEN NOW() END
+				WHERE id = $1 RETURNING cancelled_at`
+	err := db.QueryRowContext(ctx, sqlStatement, reservation.ID, reservation.Status, reservation.AssignmentID).Scan(&reservation.CancelledAt)
+	if err == sql.ErrNoRows {
+		return errors.New("reservation does not exist")
+	}
+	return err
+}
+
+// FindOverlapping implements ItemReservationRepository. It returns the active
+// reservations of an item that overlap [from, to). A zero to leaves the
+// period open-ended.
+func (r *itemReservationRepository) FindOverlapping(ctx context.Context, db DBTX, itemID int, from, to time.Time) ([]models.ItemReservation, error) {
+	sqlStatement := `SELECT ` + reservationColumns + reservationJoins + `
+				WHERE r.item_id = $1 AND r.status IN ('booked', 'checked_out')
+				AND tstzrange(r.starts_at, r.ends_at) && tstzrange($2, $3)
+				ORDER BY r.starts_at`
+	return r.findAll(ctx, db, sqlStatement, itemID, from, sql.NullTime{Time: to, Valid: !to.IsZero()})
+}
+
+// FindUpcomingByUser implements ItemReservationRepository. It returns the
+// reservations of a user that have not ended and are not cancelled.
+func (r *itemReservationRepository) FindUpcomingByUser(ctx context.Context, db DBTX, userID int) ([]models.ItemReservation, error) {
+	sqlStatement := `SELECT ` + reservationColumns + reservationJoins + `
+				WHERE r.user_id = $1 AND r.status IN ('booked', 'checked_out') AND r.ends_at > NOW()
+				ORDER BY r.starts_at`
+	return r.findAll(ctx, db, sqlStatement, userID)
+}
+
+// FindStarted implements ItemReservationRepository. It returns the booked
+// reservations that began at or before now and still wait for a hand-off.
+func (r *itemReservationRepository) FindStarted(ctx context.Context, db DBTX, now time.Time) ([]models.ItemReservation, error) {
+	sqlStatement := `SELECT ` + reservationColumns + reservationJoins + `
+				WHERE r.status = 'booked' AND r.starts_at <= $1
+				ORDER BY r.starts_at`
+	return r.findAll(ctx, db, sqlStatement, now)
+}
+
+func (r *itemReservationRepository) findAll(ctx context.Context, db DBTX, sqlStatement string, args ...interface{}) ([]models.ItemReservation, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, sqlStatement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []models.ItemReservation{}
+	for rows.Next() {
+		var reservation models.ItemReservation
+		if err := scanReservation(rows, &reservation); err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+	return reservations, rows.Err()
+}
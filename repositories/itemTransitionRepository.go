@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+type ItemTransitionRepository interface {
+	Create(ctx context.Context, db DBTX, transition *models.ItemTransition) error
+	FindByItemID(ctx context.Context, db DBTX, itemID int) ([]models.ItemTransition, error)
+}
+
+type itemTransitionRepository struct{}
+
+func NewItemTransitionRepository() ItemTransitionRepository {
+	return &itemTransitionRepository{}
+}
+
+// Create implements ItemTransitionRepository.
+func (t *itemTransitionRepository) Create(ctx context.Context, db DBTX, transition *models.ItemTransition) error {
+	if transition == nil {
+		return errors.New("transition cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO item_transitions (item_id, from_state, to_state, reason, transitioned_by)
+				VALUES ($1, NULLIF($2, '')::lifecycle_state_enum, $3, $4, NULLIF($5, 0)) RETURNING id, transitioned_at`
+	err := db.QueryRowContext(ctx, sqlStatement, transition.ItemID, transition.FromState, transition.ToState, transition.Reason, transition.TransitionedBy).
+		Scan(&transition.ID, &transition.TransitionedAt)
+	if err != nil {
+		log.Printf("Error inserting item transition: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FindByItemID implements ItemTransitionRepository. Transitions are returned
+// oldest first.
+func (t *itemTransitionRepository) FindByItemID(ctx context.Context, db DBTX, itemID int) ([]models.ItemTransition, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT t.id, t.item_id, COALESCE(t.from_state::text, ''), t.to_state, t.reason, COALESCE(t.transitioned_by, 0), COALESCE(u.username, ''), t.transitioned_at
+				FROM item_transitions t
+				LEFT JOIN users u ON u.id = t.transitioned_by
+				WHERE t.item_id = $1
+				ORDER BY t.transitioned_at, t.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transitions := []models.ItemTransition{}
+	for rows.Next() {
+		var transition models.ItemTransition
+		err := rows.Scan(&transition.ID, &transition.ItemID, &transition.FromState, &transition.ToState, &transition.Reason,
+			&transition.TransitionedBy, &transition.TransitionedByUsername, &transition.TransitionedAt)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, rows.Err()
+}
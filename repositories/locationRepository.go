@@ -0,0 +1,259 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+type LocationRepository interface {
+	Create(ctx context.Context, db DBTX, locationInput *models.Location) (*models.Location, error)
+	Update(ctx context.Context, db DBTX, locationInput *models.Location) (*models.Location, error)
+	Delete(ctx context.Context, db DBTX, id int) error
+	FindAll(ctx context.Context, db DBTX) ([]models.Location, error)
+	FindByID(ctx context.Context, db DBTX, id int) (*models.Location, error)
+	FindChildTypes(ctx context.Context, db DBTX, id int) ([]models.LocationType, error)
+	CountUsage(ctx context.Context, db DBTX, id int) (int, int, error)
+	FindDepreciationPolicies(ctx context.Context, db DBTX, id int) ([]models.DepreciationPolicy, error)
+}
+
+type locationRepository struct{}
+
+// locationPathsCTE builds the full path of every location, such as
+// "Head Office > Tower A > Floor 3 > Room 301". Use it after WITH RECURSIVE.
+const locationPathsCTE = `location_paths AS (
+		SELECT id, name::text AS path FROM locations WHERE parent_id IS NULL
+		UNION
+		SELECT l.id, lp.path || ' > ' || l.name FROM locations l JOIN location_paths lp ON l.parent_id = lp.id
+	)`
+
+// locationScopeCTE selects location $1 and all of its active sublocations
+const locationScopeCTE = `location_scope AS (
+		SELECT id FROM locations WHERE id = $1
+		UNION
+		SELECT l.id FROM locations l JOIN location_scope s ON l.parent_id = s.id WHERE l.status = 'active'
+	)`
+
+// locationColumns selects a location (alias l) joined to location_paths (alias lp)
+const locationColumns = `l.id, l.name, l.type, COALESCE(l.parent_id, 0), COALESCE(l.description, ''), lp.path, l.deleted_at`
+
+func scanLocation(row rowScanner, location *models.Location) error {
+	var parentID int
+	err := row.Scan(&location.ID, &location.Name, &location.Type, &parentID, &location.Description, &location.Path, &location.DeletedAt)
+	if err == nil && parentID != 0 {
+		location.ParentID = &parentID
+	}
+	return err
+}
+
+func NewLocationRepository() LocationRepository {
+	return &locationRepository{}
+}
+
+// Create implements LocationRepository.
+func (l *locationRepository) Create(ctx context.Context, db DBTX, locationInput *models.Location) (*models.Location, error) {
+	if locationInput == nil {
+		return nil, errors.New("locationInput cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO locations (name, type, parent_id, description) VALUES ($1, $2, NULLIF($3, 0), $4) RETURNING id`
+	err := db.QueryRowContext(ctx, sqlStatement, locationInput.Name, locationInput.Type, locationInput.ParentID, locationInput.Description).Scan(&locationInput.ID)
+	if err != nil {
+		log.Printf("Error inserting location: %v", err.Error())
+		return nil, err
+	}
+	return l.FindByID(ctx, db, locationInput.ID)
+}
+
+// Update implements LocationRepository.
+func (l *locationRepository) Update(ctx context.Context, db DBTX, locationInput *models.Location) (*models.Location, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	fields := make(map[string]interface{})
+
+	if locationInput.Name != "" {
+		fields["name"] = locationInput.Name
+	}
+	if locationInput.Type != "" {
+		fields["type"] = locationInput.Type
+	}
+	if locationInput.ParentID != nil {
+		if *locationInput.ParentID == 0 {
+			fields["parent_id"] = nil
+		} else {
+			fields["parent_id"] = *locationInput.ParentID
+		}
+	}
+	if locationInput.Description != "" {
+		fields["description"] = locationInput.Description
+	}
+
+	fields["updated_at"] = time.Now()
+	setClauses := []string{}
+	values := []interface{}{}
+	index := 1
+	for field, value := range fields {
+		setClauses = append(setClauses, field+"=$"+strconv.Itoa(index))
+		values = append(values, value)
+		index++
+	}
+
+	sqlStatement := fmt.Sprintf("UPDATE locations SET %s WHERE id = $%d AND status = 'active' RETURNING id", strings.Join(setClauses, ", "), index)
+	values = append(values, locationInput.ID)
+
+	var id int
+	err := db.QueryRowContext(ctx, sqlStatement, values...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("location does not exist")
+	} else if err != nil {
+		log.Printf("Error updating location: %v", err.Error())
+		return nil, err
+	}
+	return l.FindByID(ctx, db, id)
+}
+
+// Delete implements LocationRepository.
+func (l *locationRepository) Delete(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `UPDATE locations SET status = 'deleted', deleted_at = NOW() WHERE id = $1 AND status = 'active'`
+	result, err := db.ExecContext(ctx, sqlStatement, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("location does not exist")
+	}
+	return nil
+}
+
+// FindAll implements LocationRepository.
+func (l *locationRepository) FindAll(ctx context.Context, db DBTX) ([]models.Location, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ` + locationPathsCTE + `
+				SELECT ` + locationColumns + ` FROM locations l
+				JOIN location_paths lp ON lp.id = l.id
+				WHERE l.status = 'active'
+				ORDER BY lp.path`
+	rows, err := db.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []models.Location
+	for rows.Next() {
+		var location models.Location
+		if err := scanLocation(rows, &location); err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	return locations, rows.Err()
+}
+
+// FindByID implements LocationRepository.
+func (l *locationRepository) FindByID(ctx context.Context, db DBTX, id int) (*models.Location, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var location models.Location
+	sqlStatement := `WITH RECURSIVE ` + locationPathsCTE + `
+				SELECT ` + locationColumns + ` FROM locations l
+				JOIN location_paths lp ON lp.id = l.id
+				WHERE l.id = $1 AND l.status = 'active'`
+	err := scanLocation(db.QueryRowContext(ctx, sqlStatement, id), &location)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("location does not exist")
+	} else if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// FindChildTypes implements LocationRepository. It returns the distinct types
+// of the active direct sublocations of a location.
+func (l *locationRepository) FindChildTypes(ctx context.Context, db DBTX, id int) ([]models.LocationType, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT DISTINCT type FROM locations WHERE parent_id = $1 AND status = 'active'`
+	rows, err := db.QueryContext(ctx, sqlStatement, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []models.LocationType
+	for rows.Next() {
+		var locationType models.LocationType
+		if err := rows.Scan(&locationType); err != nil {
+			return nil, err
+		}
+		types = append(types, locationType)
+	}
+	return types, rows.Err()
+}
+
+// CountUsage implements LocationRepository. It returns the number of active
+// items and active direct sublocations of a location.
+func (l *locationRepository) CountUsage(ctx context.Context, db DBTX, id int) (int, int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var itemCount, sublocationCount int
+	sqlStatement := `SELECT (SELECT COUNT(*) FROM items WHERE location_id = $1 AND status = 'active'),
+				(SELECT COUNT(*) FROM locations WHERE parent_id = $1 AND status = 'active')`
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&itemCount, &sublocationCount)
+	if err != nil {
+		return 0, 0, err
+	}
+	return itemCount, sublocationCount, nil
+}
+
+// FindDepreciationPolicies implements LocationRepository. It returns the
+// policies of the active, undisposed items at a location or any of its
+// sublocations.
+func (l *locationRepository) FindDepreciationPolicies(ctx context.Context, db DBTX, id int) ([]models.DepreciationPolicy, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ` + locationScopeCTE + `
+				SELECT ` + depreciationPolicyColumns + ` FROM items i
+				JOIN categories c ON i.category_id = c.id
+				WHERE i.status = 'active' AND i.lifecycle_state <> 'disposed' AND i.location_id IN (SELECT id FROM location_scope)
+				ORDER BY i.id`
+	rows, err := db.QueryContext(ctx, sqlStatement, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []models.DepreciationPolicy
+	for rows.Next() {
+		var policy models.DepreciationPolicy
+		if err := scanDepreciationPolicy(rows, &policy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/lib/pq"
+)
+
+type MaintenancePlanRepository interface {
+	Create(ctx context.Context, db DBTX, plan *models.MaintenancePlan) error
+	Delete(ctx context.Context, db DBTX, id int) error
+	FindAll(ctx context.Context, db DBTX) ([]models.MaintenancePlan, error)
+	AppliesTo(ctx context.Context, db DBTX, planID, itemID int) (bool, error)
+	RefreshSchedules(ctx context.Context, db DBTX) (int, int, error)
+	Complete(ctx context.Context, db DBTX, record *models.MaintenanceRecord) error
+	FindDue(ctx context.Context, db DBTX, withinDays int, itemIDs []int) ([]models.MaintenanceDue, error)
+}
+
+// planItemsCTE pairs every plan with the active items it covers: the item of
+// an item plan, or the items of a category plan's category and subcategories.
+// Retired, disposed and lost items are no longer maintained.
+const planItemsCTE = `plan_categories AS (
+		SELECT id AS plan_id, category_id FROM maintenance_plans WHERE category_id IS NOT NULL
+		UNION
+		SELECT pc.plan_id, c.id FROM categories c JOIN plan_categories pc ON c.parent_id = pc.category_id
+	),
+	plan_items AS (
+		SELECT p.id AS plan_id, i.id AS item_id FROM maintenance_plans p JOIN items i ON i.id = p.item_id
+		WHERE i.status = 'active' AND i.lifecycle_state NOT IN ('retired', 'disposed', 'lost')
+		UNION
+		SELECT pc.plan_id, i.id FROM plan_categories pc JOIN items i ON i.category_id = pc.category_id
+		WHERE i.status = 'active' AND i.lifecycle_state NOT IN ('retired', 'disposed', 'lost')
+	)`
+
+type maintenancePlanRepository struct{}
+
+func NewMaintenancePlanRepository() MaintenancePlanRepository {
+	return &maintenancePlanRepository{}
+}
+
+// Create implements MaintenancePlanRepository.
+func (m *maintenancePlanRepository) Create(ctx context.Context, db DBTX, plan *models.MaintenancePlan) error {
+	if plan == nil {
+		return errors.New("maintenance plan cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO maintenance_plans (name, item_id, category_id, interval_days, description)
+				VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), $4, NULLIF($5, '')) RETURNING id, created_at`
+	err := db.QueryRowContext(ctx, sqlStatement, plan.Name, plan.ItemID, plan.CategoryID, plan.IntervalDays, plan.Description).
+		Scan(&plan.ID, &plan.CreatedAt)
+	if err != nil {
+		log.Printf("Error inserting maintenance plan: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Delete implements MaintenancePlanRepository. The plan's schedules go with
+// it; records that completed it are kept.
+func (m *maintenancePlanRepository) Delete(ctx context.Context, db DBTX, id int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, `DELETE FROM maintenance_plans WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("maintenance plan does not exist")
+	}
+	return nil
+}
+
+// FindAll implements MaintenancePlanRepository.
+func (m *maintenancePlanRepository) FindAll(ctx context.Context, db DBTX) ([]models.MaintenancePlan, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `
+				SELECT p.id, p.name, COALESCE(p.item_id, 0), COALESCE(i.name, ''), COALESCE(p.category_id, 0), COALESCE(cp.path, ''),
+				p.interval_days, COALESCE(p.description, ''), p.created_at
+				FROM maintenance_plans p
+				LEFT JOIN items i ON i.id = p.item_id
+				LEFT JOIN category_paths cp ON cp.id = p.category_id
+				ORDER BY p.id`
+	rows, err := db.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []models.MaintenancePlan{}
+	for rows.Next() {
+		var plan models.MaintenancePlan
+		err := rows.Scan(&plan.ID, &plan.Name, &plan.ItemID, &plan.ItemName, &plan.CategoryID, &plan.CategoryPath,
+			&plan.IntervalDays, &plan.Description, &plan.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
+
+// AppliesTo implements MaintenancePlanRepository.
+func (m *maintenancePlanRepository) AppliesTo(ctx context.Context, db DBTX, planID, itemID int) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var applies bool
+	sqlStatement := `WITH RECURSIVE ` + planItemsCTE + `
+				SELECT EXISTS (SELECT 1 FROM plan_items WHERE plan_id = $1 AND item_id = $2)`
+	if err := db.QueryRowContext(ctx, sqlStatement, planID, itemID).Scan(&applies); err != nil {
+		return false, err
+	}
+	return applies, nil
+}
+
+// RefreshSchedules implements MaintenancePlanRepository. It adds a schedule
+// for every item a plan newly covers and drops the schedules of items it no
+// longer covers, returning how many were added and removed. A new schedule
+// is due one interval after the last record completing the plan, or else
+// after the later of the purchase date and the plan's creation.
+func (m *maintenancePlanRepository) RefreshSchedules(ctx context.Context, db DBTX) (int, int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	insertStatement := `WITH RECURSIVE ` + planItemsCTE + `
+				INSERT INTO maintenance_schedules (plan_id, item_id, last_done_on, next_due_on)
+				SELECT p.id, i.id, last.performed_on,
+				COALESCE(last.performed_on, GREATEST(i.purchase_date, p.created_at::date)) + p.interval_days
+				FROM plan_items pi
+				JOIN maintenance_plans p ON p.id = pi.plan_id
+				JOIN items i ON i.id = pi.item_id
+				CROSS JOIN LATERAL (
+					SELECT MAX(r.performed_on) AS performed_on FROM maintenance_records r WHERE r.plan_id = p.id AND r.item_id = i.id
+				) last
+				ON CONFLICT (plan_id, item_id) DO NOTHING`
+	result, err := db.ExecContext(ctx, insertStatement)
+	if err != nil {
+		return 0, 0, err
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	deleteStatement := `WITH RECURSIVE ` + planItemsCTE + `
+				DELETE FROM maintenance_schedules s
+				WHERE NOT EXISTS (SELECT 1 FROM plan_items pi WHERE pi.plan_id = s.plan_id AND pi.item_id = s.item_id)`
+	result, err = db.ExecContext(ctx, deleteStatement)
+	if err != nil {
+		return 0, 0, err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(added), int(removed), nil
+}
+
+// Complete implements MaintenancePlanRepository. It advances the schedule of
+// the record's plan for the record's item to one interval after the record.
+// A record older than the last completion leaves the schedule as it is.
+func (m *maintenancePlanRepository) Complete(ctx context.Context, db DBTX, record *models.MaintenanceRecord) error {
+	if record == nil {
+		return errors.New("maintenance record cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `INSERT INTO maintenance_schedules (plan_id, item_id, last_done_on, next_due_on)
+				SELECT p.id, $2::int, $3::date, $3::date + p.interval_days FROM maintenance_plans p WHERE p.id = $1
+				ON CONFLICT (plan_id, item_id) DO UPDATE SET last_done_on = EXCLUDED.last_done_on, next_due_on = EXCLUDED.next_due_on
+				WHERE maintenance_schedules.last_done_on IS NULL OR maintenance_schedules.last_done_on <= EXCLUDED.last_done_on`
+	_, err := db.ExecContext(ctx, sqlStatement, record.PlanID, record.ItemID, record.PerformedOn)
+	if err != nil {
+		log.Printf("Error advancing maintenance schedule: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FindDue implements MaintenancePlanRepository. It returns the schedules of
+// active items due within withinDays of today, overdue ones included, soonest
+// first. A negative withinDays returns only overdue schedules. Non-empty
+// itemIDs limits the result to those items.
+func (m *maintenancePlanRepository) FindDue(ctx context.Context, db DBTX, withinDays int, itemIDs []int) ([]models.MaintenanceDue, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var ids pq.Int64Array
+	for _, id := range itemIDs {
+		ids = append(ids, int64(id))
+	}
+	sqlStatement := `WITH RECURSIVE ` + categoryPathsCTE + `, ` + locationPathsCTE + `
+				SELECT s.plan_id, p.name, s.item_id, i.name, cp.path, COALESCE(lp.path, ''), p.interval_days, s.last_done_on, s.next_due_on,
+				s.next_due_on - CURRENT_DATE
+				FROM maintenance_schedules s
+				JOIN maintenance_plans p ON p.id = s.plan_id
+				JOIN items i ON i.id = s.item_id
+				JOIN category_paths cp ON cp.id = i.category_id
+				LEFT JOIN location_paths lp ON lp.id = i.location_id
+				WHERE i.status = 'active' AND i.lifecycle_state NOT IN ('retired', 'disposed', 'lost')
+				AND s.next_due_on <= CURRENT_DATE + $1::int
+				AND ($2::int[] IS NULL OR s.item_id = ANY($2))
+				ORDER BY s.next_due_on, s.item_id, s.plan_id`
+	rows, err := db.QueryContext(ctx, sqlStatement, withinDays, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dueList := []models.MaintenanceDue{}
+	for rows.Next() {
+		var due models.MaintenanceDue
+		err := rows.Scan(&due.PlanID, &due.PlanName, &due.ItemID, &due.ItemName, &due.CategoryPath, &due.LocationPath, &due.IntervalDays,
+			&due.LastDoneOn, &due.NextDueOn, &due.DaysUntilDue)
+		if err != nil {
+			return nil, err
+		}
+		due.Overdue = due.DaysUntilDue < 0
+		dueList = append(dueList, due)
+	}
+	return dueList, rows.Err()
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/lib/pq"
+)
+
+type MaintenanceRepository interface {
+	Create(ctx context.Context, db DBTX, record *models.MaintenanceRecord) error
+	FindByItemID(ctx context.Context, db DBTX, itemID int) ([]models.MaintenanceRecord, error)
+	FindPurgeableAttachments(ctx context.Context, db DBTX, cutoff time.Time) ([]string, error)
+}
+
+type maintenanceRepository struct{}
+
+func NewMaintenanceRepository() MaintenanceRepository {
+	return &maintenanceRepository{}
+}
+
+// Create implements MaintenanceRepository.
+func (m *maintenanceRepository) Create(ctx context.Context, db DBTX, record *models.MaintenanceRecord) error {
+	if record == nil {
+		return errors.New("maintenance record cannot be nil")
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	if record.AttachmentURLs == nil {
+		record.AttachmentURLs = []string{}
+	}
+	sqlStatement := `INSERT INTO maintenance_records (item_id, maintenance_type, performed_on, vendor, cost, description, attachment_urls, recorded_by, plan_id, is_warranty_claim)
+				VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, NULLIF($8, 0), NULLIF($9, 0), $10) RETURNING id, created_at,
+				COALESCE((SELECT $3::date <= warranty_ends_on FROM items WHERE id = $1), false)`
+	err := db.QueryRowContext(ctx, sqlStatement, record.ItemID, record.Type, record.PerformedOn, record.Vendor, record.Cost, record.Description,
+		pq.Array(record.AttachmentURLs), record.RecordedBy, record.PlanID, record.IsWarrantyClaim).Scan(&record.ID, &record.CreatedAt, &record.UnderWarranty)
+	if err != nil {
+		log.Printf("Error inserting maintenance record: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// FindByItemID implements MaintenanceRepository. Records are returned most
+// recent first.
+func (m *maintenanceRepository) FindByItemID(ctx context.Context, db DBTX, itemID int) ([]models.MaintenanceRecord, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT m.id, m.item_id, m.maintenance_type, m.performed_on, COALESCE(m.vendor, ''), m.cost, m.description, m.attachment_urls,
+				COALESCE(m.recorded_by, 0), COALESCE(u.username, ''), COALESCE(m.plan_id, 0), m.is_warranty_claim,
+				COALESCE(m.performed_on <= i.warranty_ends_on, false), m.created_at
+				FROM maintenance_records m
+				JOIN items i ON i.id = m.item_id
+				LEFT JOIN users u ON u.id = m.recorded_by
+				WHERE m.item_id = $1
+				ORDER BY m.performed_on DESC, m.id DESC`
+	rows, err := db.QueryContext(ctx, sqlStatement, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []models.MaintenanceRecord{}
+	for rows.Next() {
+		var record models.MaintenanceRecord
+		err := rows.Scan(&record.ID, &record.ItemID, &record.Type, &record.PerformedOn, &record.Vendor, &record.Cost, &record.Description,
+			pq.Array(&record.AttachmentURLs), &record.RecordedBy, &record.RecordedByUsername, &record.PlanID, &record.IsWarrantyClaim,
+			&record.UnderWarranty, &record.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// FindPurgeableAttachments implements MaintenanceRepository. It returns the
+// attachment paths of the items a purge with this cutoff will remove.
+func (m *maintenanceRepository) FindPurgeableAttachments(ctx context.Context, db DBTX, cutoff time.Time) ([]string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT unnest(m.attachment_urls) FROM maintenance_records m
+				JOIN items i ON i.id = m.item_id
+				WHERE i.status = 'deleted' AND COALESCE(i.deleted_at, i.updated_at) < $1`
+	rows, err := db.QueryContext(ctx, sqlStatement, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachmentURLs := []string{}
+	for rows.Next() {
+		var attachmentURL string
+		if err := rows.Scan(&attachmentURL); err != nil {
+			return nil, err
+		}
+		attachmentURLs = append(attachmentURLs, attachmentURL)
+	}
+	return attachmentURLs, rows.Err()
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+type ReportRepository interface {
+	FindAssetRecords(ctx context.Context, db DBTX) ([]models.AssetRecord, error)
+}
+
+type reportRepository struct{}
+
+func NewReportRepository() ReportRepository {
+	return &reportRepository{}
+}
+
+// FindAssetRecords implements ReportRepository. Deleted items are left out.
+func (r *reportRepository) FindAssetRecords(ctx context.Context, db DBTX) ([]models.AssetRecord, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sqlStatement := `SELECT ` + depreciationPolicyColumns + `, i.name, c.id, c.name, i.lifecycle_state::text,
+				(SELECT d.disposed_on FROM item_disposals d WHERE d.item_id = i.id) FROM items i
+				JOIN categories c ON i.category_id = c.id
+				WHERE i.status <> 'deleted'
+				ORDER BY c.name, c.id, i.id`
+	rows, err := db.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []models.AssetRecord
+	for rows.Next() {
+		var record models.AssetRecord
+		policy := &record.Policy
+		err := rows.Scan(&policy.ItemID, &policy.Cost, &policy.PurchaseDate, &policy.Rate, &policy.Method, &policy.UsefulLifeYears, &policy.SalvageValue,
+			&record.Row.ItemName, &policy.CategoryID, &policy.CategoryName, &record.Row.Status, &record.DisposedOn)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
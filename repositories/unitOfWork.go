@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// QueryTimeout bounds every repository call. A zero value disables the limit.
+var QueryTimeout = 5 * time.Second
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so repository methods can
+// run either directly on the pool or inside a transaction owned by a service.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// UnitOfWork lets services decide transaction boundaries across repositories.
+type UnitOfWork struct {
+	DB *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{DB: db}
+}
+
+// WithinTransaction runs fn inside a single transaction bound to ctx. The
+// transaction is committed when fn returns nil and rolled back on error,
+// panic or cancellation of ctx.
+func (u *UnitOfWork) WithinTransaction(ctx context.Context, fn func(tx DBTX) error) (err error) {
+	tx, err := u.DB.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Error starting transaction: %v", err.Error())
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // Re-panic after rollback
+		} else if err != nil {
+			log.Printf("Rolling back transaction due to error: %v", err.Error())
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("Error committing transaction: %v", err.Error())
+		return err
+	}
+	return nil
+}
+
+// withQueryTimeout derives the context used for a single repository call.
+func withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, QueryTimeout)
+}
@@ -1,7 +1,8 @@
 package routers
 
 import (
-	"github.com/Safiramdhn/project-app-inventaris-golang-safira/database"
+	"database/sql"
+
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/handlers"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/middlewares"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
@@ -9,27 +10,63 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func NewRouter() chi.Router {
-	db := database.NewPostgresDB()
+func NewRouter(db *sql.DB) chi.Router {
+	uow := repositories.NewUnitOfWork(db)
 	r := chi.NewRouter()
 
 	// Initialize handlers
-	authRepo := repositories.NewAuthRepository(db)
-	authService := services.NewAuthService(authRepo)
+	authRepo := repositories.NewAuthRepository()
+	authService := services.NewAuthService(uow, authRepo)
 	AuthHandler := handlers.NewAuthHandler(authService)
+	authMiddleware := middlewares.NewAuthMiddleware(authService)
+
+	itemInvesmentRepo := repositories.NewItemInvestmentRepository()
 
-	categoryRepo := repositories.NewCategoryRepository(db)
-	categoryService := services.NewCategoryService(categoryRepo)
+	categoryRepo := repositories.NewCategoryRepository()
+	categoryService := services.NewCategoryService(uow, categoryRepo, itemInvesmentRepo)
 	CategoryHandler := handlers.NewCategoryHandler(categoryService)
 
-	itemRepo := repositories.NewItemRepository(db)
-	itemService := services.NewItemService(itemRepo)
+	locationRepo := repositories.NewLocationRepository()
+	locationService := services.NewLocationService(uow, locationRepo)
+	locationHandler := handlers.NewLocationHandler(locationService)
+
+	maintenancePlanRepo := repositories.NewMaintenancePlanRepository()
+
+	itemRepo := repositories.NewItemRepository()
+	itemAssignmentRepo := repositories.NewItemAssignmentRepository()
+	itemReservationRepo := repositories.NewItemReservationRepository()
+	itemService := services.NewItemService(uow, itemRepo, itemInvesmentRepo, locationRepo, maintenancePlanRepo, repositories.NewItemTransitionRepository(), itemAssignmentRepo)
 	itemHandler := handlers.NewItemHandler(itemService)
 
-	itemInvesmentRepo := repositories.NewItemInvestmentRepository(db)
-	itemInvesmentService := services.NewItemInvestmentService(itemInvesmentRepo)
+	itemDisposalService := services.NewItemDisposalService(uow, itemService, repositories.NewDepreciationRepository(), repositories.NewItemDisposalRepository())
+	itemDisposalHandler := handlers.NewItemDisposalHandler(itemDisposalService)
+
+	itemMovementService := services.NewItemMovementService(uow, itemRepo, locationRepo, repositories.NewItemMovementRepository())
+	itemMovementHandler := handlers.NewItemMovementHandler(itemMovementService)
+
+	itemAssignmentService := services.NewItemAssignmentService(uow, itemService, itemAssignmentRepo, itemReservationRepo)
+	itemAssignmentHandler := handlers.NewItemAssignmentHandler(itemAssignmentService)
+
+	itemReservationService := services.NewItemReservationService(uow, itemService, itemAssignmentRepo, itemReservationRepo)
+	itemReservationHandler := handlers.NewItemReservationHandler(itemReservationService)
+
+	maintenanceRepo := repositories.NewMaintenanceRepository()
+	maintenanceService := services.NewMaintenanceService(uow, itemRepo, categoryRepo, maintenanceRepo, maintenancePlanRepo)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceService)
+
+	itemInvesmentService := services.NewItemInvestmentService(uow, itemInvesmentRepo)
 	itemInvesmentHandler := handlers.NewItemInvestmentHandler(*itemInvesmentService)
 
+	reportRepo := repositories.NewReportRepository()
+	reportService := services.NewReportService(uow, reportRepo, itemRepo)
+	reportHandler := handlers.NewReportHandler(reportService)
+
+	auditService := services.NewAuditService(uow, itemService, categoryRepo, repositories.NewAuditRepository())
+	auditHandler := handlers.NewAuditHandler(auditService)
+
+	archiveService := services.NewArchiveService(uow, itemRepo, categoryRepo, maintenanceRepo)
+	archiveHandler := handlers.NewArchiveHandler(archiveService)
+
 	// Initialize router
 	r.Route("/api", func(r chi.Router) {
 		r.Route("/auth", func(r chi.Router) {
@@ -38,27 +75,91 @@ func NewRouter() chi.Router {
 		})
 
 		r.Route("/categories", func(r chi.Router) {
-			r.With(middlewares.AuthMiddleware).Post("/", CategoryHandler.CreateCategoryHandler)
-			r.With(middlewares.AuthMiddleware).Put("/{id}", CategoryHandler.UpdateCategoryHandler)
-			r.With(middlewares.AuthMiddleware).Delete("/{id}", CategoryHandler.DeleteCategoryHandler)
-			r.With(middlewares.AuthMiddleware).Get("/", CategoryHandler.GetCategoriesHandler)
-			r.With(middlewares.AuthMiddleware).Get("/{id}", CategoryHandler.GetCategoryByIDHandler)
+			r.With(authMiddleware.Authenticate).Post("/", CategoryHandler.CreateCategoryHandler)
+			r.With(authMiddleware.Authenticate).Put("/{id}", CategoryHandler.UpdateCategoryHandler)
+			r.With(authMiddleware.Authenticate).Delete("/{id}", CategoryHandler.DeleteCategoryHandler)
+			r.With(authMiddleware.Authenticate).Get("/", CategoryHandler.GetCategoriesHandler)
+			r.With(authMiddleware.Authenticate).Get("/tree", CategoryHandler.GetCategoryTreeHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}", CategoryHandler.GetCategoryByIDHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/restore", CategoryHandler.RestoreCategoryHandler)
+		})
+
+		r.Route("/locations", func(r chi.Router) {
+			r.With(authMiddleware.Authenticate).Post("/", locationHandler.CreateLocationHandler)
+			r.With(authMiddleware.Authenticate).Put("/{id}", locationHandler.UpdateLocationHandler)
+			r.With(authMiddleware.Authenticate).Delete("/{id}", locationHandler.DeleteLocationHandler)
+			r.With(authMiddleware.Authenticate).Get("/", locationHandler.GetLocationsHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}", locationHandler.GetLocationByIDHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/summary", locationHandler.GetLocationSummaryHandler)
 		})
 
 		r.Route("/items", func(r chi.Router) {
-			r.With(middlewares.AuthMiddleware).Post("/", itemHandler.CreateItemHandler)
-			r.With(middlewares.AuthMiddleware).Get("/{id}", itemHandler.GetItemByIDHandler)
-			r.With(middlewares.AuthMiddleware).Put("/{id}", itemHandler.UpdateItemHandler)
-			r.With(middlewares.AuthMiddleware).Delete("/{id}", itemHandler.DeleteItemHandler)
-			r.With(middlewares.AuthMiddleware).Get("/", itemHandler.GetAllItemsHandler)
-			r.With(middlewares.AuthMiddleware).Get("/need-replacement", itemHandler.GetReplacementItemsHandler)
+			r.With(authMiddleware.Authenticate).Post("/", itemHandler.CreateItemHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}", itemHandler.GetItemByIDHandler)
+			r.With(authMiddleware.Authenticate).Put("/{id}", itemHandler.UpdateItemHandler)
+			r.With(authMiddleware.Authenticate).Delete("/{id}", itemHandler.DeleteItemHandler)
+			r.With(authMiddleware.Authenticate).Get("/", itemHandler.GetAllItemsHandler)
+			r.With(authMiddleware.Authenticate).Get("/need-replacement", itemHandler.GetReplacementItemsHandler)
+			r.With(authMiddleware.Authenticate).Get("/warranty-expiring", itemHandler.GetWarrantyExpiringHandler)
+			r.With(authMiddleware.Authenticate).Get("/labels.pdf", itemHandler.GetLabelSheetHandler)
+			r.With(authMiddleware.Authenticate).Get("/lookup", itemHandler.LookupItemHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/label.png", itemHandler.GetItemLabelPNGHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/label.svg", itemHandler.GetItemLabelSVGHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/restore", itemHandler.RestoreItemHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/transition", itemHandler.TransitionItemHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/transitions", itemHandler.GetItemTransitionsHandler)
+			r.With(authMiddleware.Authenticate, authMiddleware.RequireAdmin).Post("/{id}/dispose", itemDisposalHandler.DisposeItemHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/move", itemMovementHandler.MoveItemHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/movements", itemMovementHandler.GetItemMovementsHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/checkout", itemAssignmentHandler.CheckoutItemHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/checkin", itemAssignmentHandler.CheckinItemHandler)
+			r.With(authMiddleware.Authenticate).Get("/mine", itemAssignmentHandler.GetMyItemsHandler)
+			r.With(authMiddleware.Authenticate).Get("/overdue", itemAssignmentHandler.GetOverdueItemsHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/reservations", itemReservationHandler.BookReservationHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/availability", itemReservationHandler.GetAvailabilityHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/maintenance", maintenanceHandler.CreateMaintenanceRecordHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/maintenance", maintenanceHandler.GetMaintenanceRecordsHandler)
 
 			r.Route("/investment", func(r chi.Router) {
-				r.With(middlewares.AuthMiddleware).Get("/", itemInvesmentHandler.CountAllItemInvestmentsHandler)
-				r.With(middlewares.AuthMiddleware).Get("/{id}", itemInvesmentHandler.GetItemInvesmentByItemIdHandler)
+				r.With(authMiddleware.Authenticate).Get("/", itemInvesmentHandler.CountAllItemInvestmentsHandler)
+				r.With(authMiddleware.Authenticate).Get("/summary", itemInvesmentHandler.GetInvestmentSummaryHandler)
+				r.With(authMiddleware.Authenticate).Get("/{id}", itemInvesmentHandler.GetItemInvesmentByItemIdHandler)
 			})
 		})
 
+		r.Route("/maintenance", func(r chi.Router) {
+			r.With(authMiddleware.Authenticate).Get("/due", maintenanceHandler.GetMaintenanceDueHandler)
+			r.With(authMiddleware.Authenticate).Post("/plans", maintenanceHandler.CreateMaintenancePlanHandler)
+			r.With(authMiddleware.Authenticate).Get("/plans", maintenanceHandler.GetMaintenancePlansHandler)
+			r.With(authMiddleware.Authenticate).Delete("/plans/{id}", maintenanceHandler.DeleteMaintenancePlanHandler)
+		})
+
+		r.Route("/reservations", func(r chi.Router) {
+			r.With(authMiddleware.Authenticate).Get("/mine", itemReservationHandler.GetMyReservationsHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/cancel", itemReservationHandler.CancelReservationHandler)
+		})
+
+		r.Route("/audits", func(r chi.Router) {
+			r.With(authMiddleware.Authenticate).Post("/", auditHandler.StartAuditHandler)
+			r.With(authMiddleware.Authenticate).Get("/", auditHandler.GetAuditsHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}", auditHandler.GetAuditByIDHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/scans", auditHandler.RecordScanHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/scans", auditHandler.GetScansHandler)
+			r.With(authMiddleware.Authenticate).Post("/{id}/close", auditHandler.CloseAuditHandler)
+			r.With(authMiddleware.Authenticate).Get("/{id}/reconciliation", auditHandler.GetReconciliationHandler)
+			r.With(authMiddleware.Authenticate, authMiddleware.RequireAdmin).Post("/{id}/mark-missing-lost", auditHandler.MarkMissingLostHandler)
+		})
+
+		r.Route("/reports", func(r chi.Router) {
+			r.With(authMiddleware.Authenticate).Get("/asset-register", reportHandler.GetAssetRegisterHandler)
+			r.With(authMiddleware.Authenticate).Get("/replacement-forecast", reportHandler.GetReplacementForecastHandler)
+			r.With(authMiddleware.Authenticate).Get("/disposals", itemDisposalHandler.GetDisposalReportHandler)
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.With(authMiddleware.Authenticate, authMiddleware.RequireAdmin).Post("/purge", archiveHandler.PurgeHandler)
+		})
+
 	})
 
 	return r
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+type ArchiveService struct {
+	UoW             *repositories.UnitOfWork
+	ItemRepo        repositories.ItemRepository
+	CategoryRepo    repositories.CategoryRepository
+	MaintenanceRepo repositories.MaintenanceRepository
+}
+
+func NewArchiveService(uow *repositories.UnitOfWork, itemRepo repositories.ItemRepository, categoryRepo repositories.CategoryRepository, maintenanceRepo repositories.MaintenanceRepository) *ArchiveService {
+	return &ArchiveService{UoW: uow, ItemRepo: itemRepo, CategoryRepo: categoryRepo, MaintenanceRepo: maintenanceRepo}
+}
+
+// Purge permanently removes the items and categories deleted more than
+// retentionDays ago, then removes the photos and maintenance attachments of
+// the purged items.
+func (s *ArchiveService) Purge(ctx context.Context, retentionDays int) (*models.PurgeResult, error) {
+	if retentionDays < 1 {
+		return nil, errors.New("retention must be at least one day")
+	}
+
+	result := &models.PurgeResult{Cutoff: time.Now().AddDate(0, 0, -retentionDays)}
+	var photoURLs, attachmentURLs []string
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		// The maintenance records go with their items, so collect their
+		// attachments first
+		var err error
+		attachmentURLs, err = s.MaintenanceRepo.FindPurgeableAttachments(ctx, tx, result.Cutoff)
+		if err != nil {
+			return err
+		}
+
+		result.ItemsPurged, photoURLs, err = s.ItemRepo.PurgeDeleted(ctx, tx, result.Cutoff)
+		if err != nil {
+			return err
+		}
+
+		// A category can only go once its subcategories are gone, so purge
+		// the deleted tree from the leaves up
+		for {
+			purged, err := s.CategoryRepo.PurgeDeleted(ctx, tx, result.Cutoff)
+			if err != nil {
+				return err
+			}
+			if purged == 0 {
+				return nil
+			}
+			result.CategoriesPurged += purged
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to purge deleted records: %v", err.Error())
+		return nil, err
+	}
+
+	// Only remove files once the rows are gone for good, and only photos no
+	// remaining item still shows
+	for _, photoURL := range photoURLs {
+		if err := os.Remove(photoURL); err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("Failed to remove photo %s: %v", photoURL, err.Error())
+			}
+			continue
+		}
+		result.PhotosRemoved++
+	}
+	for _, attachmentURL := range attachmentURLs {
+		if err := os.Remove(attachmentURL); err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("Failed to remove attachment %s: %v", attachmentURL, err.Error())
+			}
+			continue
+		}
+		result.AttachmentsRemoved++
+	}
+	return result, nil
+}
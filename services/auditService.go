@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+// ErrAuditClosed is returned when scanning into or closing an audit that is
+// already closed
+var ErrAuditClosed = errors.New("audit session is closed")
+
+// ErrAuditOpen is returned when acting on the findings of an audit that is
+// still open
+var ErrAuditOpen = errors.New("audit session is still open")
+
+// AuditService runs physical stock-takes. Any number of auditors can scan
+// into an open session at the same time; closing it waits for the scans in
+// flight and then fixes its findings.
+type AuditService struct {
+	UoW          *repositories.UnitOfWork
+	ItemService  *ItemService
+	CategoryRepo repositories.CategoryRepository
+	AuditRepo    repositories.AuditRepository
+}
+
+func NewAuditService(uow *repositories.UnitOfWork, itemService *ItemService, categoryRepo repositories.CategoryRepository,
+	auditRepo repositories.AuditRepository) *AuditService {
+	return &AuditService{UoW: uow, ItemService: itemService, CategoryRepo: categoryRepo, AuditRepo: auditRepo}
+}
+
+// StartSession opens an audit of either a location or a category on behalf
+// of userID
+func (s *AuditService) StartSession(ctx context.Context, userID int, session models.AuditSession) (*models.AuditSession, error) {
+	session.Name = strings.TrimSpace(session.Name)
+	session.Notes = strings.TrimSpace(session.Notes)
+	if session.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(session.Name) > 100 {
+		return nil, errors.New("name must be at most 100 characters")
+	}
+	if (session.LocationID > 0) == (session.CategoryID > 0) {
+		return nil, errors.New("an audit covers either a location or a category")
+	}
+	session.StartedBy = userID
+
+	var created *models.AuditSession
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if session.LocationID > 0 {
+			if _, err := s.ItemService.LocationRepo.FindByID(ctx, tx, session.LocationID); err != nil {
+				return err
+			}
+		} else if _, err := s.CategoryRepo.FindByID(ctx, tx, session.CategoryID); err != nil {
+			return err
+		}
+		if err := s.AuditRepo.CreateSession(ctx, tx, &session); err != nil {
+			return err
+		}
+		var err error
+		created, err = s.AuditRepo.FindSession(ctx, tx, session.ID)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to start audit session: %v", err.Error())
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetSessions returns all audit sessions, open ones first
+func (s *AuditService) GetSessions(ctx context.Context) ([]models.AuditSession, error) {
+	return s.AuditRepo.FindSessions(ctx, s.UoW.DB)
+}
+
+// GetSession returns an audit session
+func (s *AuditService) GetSession(ctx context.Context, id int) (*models.AuditSession, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid audit session id")
+	}
+	return s.AuditRepo.FindSession(ctx, s.UoW.DB, id)
+}
+
+// RecordScan records an item seen by userID during an open audit. A code
+// that matches no item is still recorded and reported as unexpected. The
+// location found defaults to the audited location, which matches any item
+// recorded in one of its sublocations.
+func (s *AuditService) RecordScan(ctx context.Context, sessionID, userID int, request models.AuditScanRequest) (*models.AuditScan, error) {
+	if sessionID <= 0 {
+		return nil, errors.New("invalid audit session id")
+	}
+	tag := strings.ToUpper(strings.TrimSpace(request.Tag))
+	serial := strings.TrimSpace(request.Serial)
+	if (tag == "") == (serial == "") {
+		return nil, errors.New("either a tag or a serial number is required")
+	}
+	code := tag
+	if serial != "" {
+		code = serial
+	}
+	if len(code) > 100 {
+		return nil, errors.New("scanned code must be at most 100 characters")
+	}
+
+	scan := &models.AuditScan{
+		SessionID:   sessionID,
+		ScannedCode: code,
+		LocationID:  request.LocationID,
+		Condition:   strings.TrimSpace(request.Condition),
+		ScannedBy:   userID,
+	}
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		// A shared lock lets auditors scan concurrently but keeps the
+		// session from closing underneath them
+		status, err := s.AuditRepo.LockSession(ctx, tx, sessionID, false)
+		if err != nil {
+			return err
+		}
+		if status != models.AuditOpen {
+			return ErrAuditClosed
+		}
+
+		if scan.LocationID == 0 {
+			session, err := s.AuditRepo.FindSession(ctx, tx, sessionID)
+			if err != nil {
+				return err
+			}
+			scan.LocationID = session.LocationID
+		} else if _, err := s.ItemService.LocationRepo.FindByID(ctx, tx, scan.LocationID); err != nil {
+			return err
+		}
+
+		scan.ItemID, err = s.ItemService.ItemRepo.FindIDByIdentifier(ctx, tx, tag, serial)
+		if err != nil {
+			return err
+		}
+		return s.AuditRepo.CreateScan(ctx, tx, scan)
+	})
+	if err != nil {
+		log.Printf("Failed to record scan for audit session %d: %v", sessionID, err.Error())
+		return nil, err
+	}
+	return scan, nil
+}
+
+// GetScans returns the scans of an audit session, oldest first
+func (s *AuditService) GetScans(ctx context.Context, sessionID int) ([]models.AuditScan, error) {
+	if sessionID <= 0 {
+		return nil, errors.New("invalid audit session id")
+	}
+	if _, err := s.AuditRepo.FindSession(ctx, s.UoW.DB, sessionID); err != nil {
+		return nil, err
+	}
+	return s.AuditRepo.FindScans(ctx, s.UoW.DB, sessionID)
+}
+
+// CloseSession closes an audit on behalf of userID and returns its
+// reconciliation, which is stored and no longer changes with the items
+func (s *AuditService) CloseSession(ctx context.Context, sessionID, userID int) (*models.AuditReconciliation, error) {
+	if sessionID <= 0 {
+		return nil, errors.New("invalid audit session id")
+	}
+
+	var reconciliation *models.AuditReconciliation
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		status, err := s.AuditRepo.LockSession(ctx, tx, sessionID, true)
+		if err != nil {
+			return err
+		}
+		if status != models.AuditOpen {
+			return ErrAuditClosed
+		}
+		if err := s.AuditRepo.Close(ctx, tx, sessionID, userID); err != nil {
+			return err
+		}
+		reconciliation, err = s.reconcile(ctx, tx, sessionID)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to close audit session %d: %v", sessionID, err.Error())
+		return nil, err
+	}
+	return reconciliation, nil
+}
+
+// GetReconciliation returns the findings of an audit: those stored when it
+// was closed, or a preview while it is still open
+func (s *AuditService) GetReconciliation(ctx context.Context, sessionID int) (*models.AuditReconciliation, error) {
+	if sessionID <= 0 {
+		return nil, errors.New("invalid audit session id")
+	}
+	return s.reconcile(ctx, s.UoW.DB, sessionID)
+}
+
+func (s *AuditService) reconcile(ctx context.Context, db repositories.DBTX, sessionID int) (*models.AuditReconciliation, error) {
+	session, err := s.AuditRepo.FindSession(ctx, db, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation := &models.AuditReconciliation{
+		Session:    *session,
+		Preview:    session.Status == models.AuditOpen,
+		Missing:    []models.AuditFinding{},
+		Unexpected: []models.AuditFinding{},
+		Mislocated: []models.AuditFinding{},
+	}
+	var findings []models.AuditFinding
+	if reconciliation.Preview {
+		findings, reconciliation.ExpectedCount, reconciliation.SeenCount, err = s.AuditRepo.PreviewFindings(ctx, db, sessionID)
+	} else {
+		findings, reconciliation.ExpectedCount, reconciliation.SeenCount, err = s.AuditRepo.FindFindings(ctx, db, sessionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, finding := range findings {
+		switch finding.Kind {
+		case models.FindingMissing:
+			reconciliation.Missing = append(reconciliation.Missing, finding)
+		case models.FindingUnexpected:
+			reconciliation.Unexpected = append(reconciliation.Unexpected, finding)
+		case models.FindingMislocated:
+			reconciliation.Mislocated = append(reconciliation.Mislocated, finding)
+		}
+	}
+	return reconciliation, nil
+}
+
+// MarkMissingLost moves the items a closed audit found missing to lost on
+// behalf of userID, all in one transaction. Items whose state has since
+// moved on to one that cannot become lost are skipped.
+func (s *AuditService) MarkMissingLost(ctx context.Context, sessionID, userID int, request models.MarkLostRequest) (*models.MarkLostResult, error) {
+	if sessionID <= 0 {
+		return nil, errors.New("invalid audit session id")
+	}
+	request.Reason = strings.TrimSpace(request.Reason)
+
+	result := &models.MarkLostResult{Transitioned: []models.ItemTransition{}, Skipped: []models.SkippedItem{}}
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		session, err := s.AuditRepo.FindSession(ctx, tx, sessionID)
+		if err != nil {
+			return err
+		}
+		if session.Status != models.AuditClosed {
+			return ErrAuditOpen
+		}
+		reason := fmt.Sprintf("Missing in audit %q", session.Name)
+		if request.Reason != "" {
+			reason += ": " + request.Reason
+		}
+
+		ids, err := s.AuditRepo.FindMissingItemIDs(ctx, tx, sessionID)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			transitionRequest := models.TransitionRequest{State: models.LifecycleLost, Reason: reason}
+			transition, err := s.ItemService.transitionItem(ctx, tx, id, userID, transitionRequest)
+			if errors.Is(err, ErrInvalidTransition) {
+				result.Skipped = append(result.Skipped, models.SkippedItem{ItemID: id, Reason: err.Error()})
+				continue
+			} else if err != nil {
+				return err
+			}
+			result.Transitioned = append(result.Transitioned, *transition)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to mark missing items of audit session %d as lost: %v", sessionID, err.Error())
+		return nil, err
+	}
+	return result, nil
+}
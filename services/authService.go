@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"log"
 	"time"
@@ -12,14 +13,15 @@ import (
 )
 
 type AuthService struct {
+	UoW      *repositories.UnitOfWork
 	AuthRepo repositories.AuthRepository
 }
 
-func NewAuthService(repo repositories.AuthRepository) *AuthService {
-	return &AuthService{AuthRepo: repo}
+func NewAuthService(uow *repositories.UnitOfWork, repo repositories.AuthRepository) *AuthService {
+	return &AuthService{UoW: uow, AuthRepo: repo}
 }
 
-func (as *AuthService) RegisterUser(userDTO *models.UserDTO) (*models.User, error) {
+func (as *AuthService) RegisterUser(ctx context.Context, userDTO *models.UserDTO) (*models.User, error) {
 	// check email and username not empty
 	if userDTO.Email == "" || userDTO.Username == "" {
 		log.Println("email and username is required")
@@ -37,11 +39,11 @@ func (as *AuthService) RegisterUser(userDTO *models.UserDTO) (*models.User, erro
 	}
 	userDTO.Password = hashedPassword
 
-	return as.AuthRepo.Register(userDTO)
+	return as.AuthRepo.Register(ctx, as.UoW.DB, userDTO)
 }
 
-func (as *AuthService) LoginUser(loginRequest *models.LoginRequest) (*models.Session, error) {
-	user, err := as.AuthRepo.Login(loginRequest)
+func (as *AuthService) LoginUser(ctx context.Context, loginRequest *models.LoginRequest) (*models.Session, error) {
+	user, err := as.AuthRepo.Login(ctx, as.UoW.DB, loginRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -52,10 +54,11 @@ func (as *AuthService) LoginUser(loginRequest *models.LoginRequest) (*models.Ses
 	}
 	sessionInput := models.Session{}
 	sessionInput.UserID = user.ID
+	sessionInput.Role = user.Role
 	sessionInput.SessionToken = utils.GenerateToken()
 	sessionInput.ExpiresAt = time.Now().Add(time.Hour * 6)
 
-	session, err := as.AuthRepo.CreateSession(&sessionInput)
+	session, err := as.AuthRepo.CreateSession(ctx, as.UoW.DB, &sessionInput)
 	if err != nil {
 		log.Printf("error creating session: %v\n", err.Error())
 		return nil, err
@@ -63,6 +66,6 @@ func (as *AuthService) LoginUser(loginRequest *models.LoginRequest) (*models.Ses
 	return session, nil
 }
 
-func (as *AuthService) GetSession(sessionToken string) (*models.Session, error) {
-	return as.AuthRepo.ValidateSession(sessionToken)
+func (as *AuthService) GetSession(ctx context.Context, sessionToken string) (*models.Session, error) {
+	return as.AuthRepo.ValidateSession(ctx, as.UoW.DB, sessionToken)
 }
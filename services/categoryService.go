@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"log"
+	"time"
 
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
@@ -10,21 +12,31 @@ import (
 )
 
 type CategoryService struct {
-	CategoryRepo repositories.CategoryRepository
+	UoW                *repositories.UnitOfWork
+	CategoryRepo       repositories.CategoryRepository
+	ItemInvestmentRepo repositories.ItemInvestmentRepository
 }
 
-func NewCategoryService(repo repositories.CategoryRepository) *CategoryService {
-	return &CategoryService{CategoryRepo: repo}
+func NewCategoryService(uow *repositories.UnitOfWork, repo repositories.CategoryRepository, investmentRepo repositories.ItemInvestmentRepository) *CategoryService {
+	return &CategoryService{UoW: uow, CategoryRepo: repo, ItemInvestmentRepo: investmentRepo}
 }
 
-func (cs *CategoryService) CreateCategory(categoryInput models.Category) (*models.Category, error) {
+func (cs *CategoryService) CreateCategory(ctx context.Context, categoryInput models.Category) (*models.Category, error) {
 	// Validate input
 	if err := validations.ValidateCategoryInput(&categoryInput); err != nil {
 		return nil, err
 	}
 
 	// Attempt to create the category
-	category, err := cs.CategoryRepo.Create(&categoryInput)
+	var category *models.Category
+	err := cs.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if err := cs.checkParent(ctx, tx, categoryInput); err != nil {
+			return err
+		}
+		created, err := cs.CategoryRepo.Create(ctx, tx, &categoryInput)
+		category = created
+		return err
+	})
 	if err != nil {
 		log.Printf("Failed to create category: %v", err.Error()) // Log the error
 		return nil, err
@@ -33,7 +45,7 @@ func (cs *CategoryService) CreateCategory(categoryInput models.Category) (*model
 	return category, nil
 }
 
-func (cs *CategoryService) UpdateCategory(categoryInput models.Category) (*models.Category, error) {
+func (cs *CategoryService) UpdateCategory(ctx context.Context, categoryInput models.Category) (*models.Category, error) {
 	// Validate input
 	if categoryInput.ID == 0 {
 		return nil, errors.New("category ID is required")
@@ -43,7 +55,15 @@ func (cs *CategoryService) UpdateCategory(categoryInput models.Category) (*model
 	}
 
 	// Attempt to update the category
-	category, err := cs.CategoryRepo.Update(&categoryInput)
+	var category *models.Category
+	err := cs.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if err := cs.checkParent(ctx, tx, categoryInput); err != nil {
+			return err
+		}
+		updated, err := cs.CategoryRepo.Update(ctx, tx, &categoryInput)
+		category = updated
+		return err
+	})
 	if err != nil {
 		log.Printf("Failed to update category: %v", err.Error()) // Log the error
 		return nil, err
@@ -52,13 +72,57 @@ func (cs *CategoryService) UpdateCategory(categoryInput models.Category) (*model
 	return category, nil
 }
 
-func (cs *CategoryService) DeleteCategory(id int) error {
+// DeleteCategory soft-deletes a category. A category that still has active
+// items or subcategories is only deleted when options say what to do with
+// them; otherwise a *models.CategoryInUseError is returned.
+func (cs *CategoryService) DeleteCategory(ctx context.Context, id int, options models.CategoryDeleteOptions) error {
 	if id <= 0 {
 		return errors.New("invalid category id")
 	}
+	if options.ReassignTo != 0 && options.Force {
+		return errors.New("reassign_to and force cannot be combined")
+	}
+	if options.ReassignTo == id {
+		return errors.New("cannot reassign items to the category being deleted")
+	}
 
 	// Attempt to delete the category
-	err := cs.CategoryRepo.Delete(id)
+	err := cs.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if err := cs.CategoryRepo.LockHierarchy(ctx, tx); err != nil {
+			return err
+		}
+		if err := cs.CategoryRepo.LockCategory(ctx, tx, id); err != nil {
+			return err
+		}
+		itemCount, subcategoryCount, err := cs.CategoryRepo.CountUsage(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if itemCount > 0 || subcategoryCount > 0 {
+			switch {
+			case options.ReassignTo > 0:
+				if err := cs.checkReassignTarget(ctx, tx, id, options.ReassignTo); err != nil {
+					return err
+				}
+				moved, err := cs.CategoryRepo.Reassign(ctx, tx, id, options.ReassignTo)
+				if err != nil {
+					return err
+				}
+				log.Printf("Moved %d items from category %d to %d", moved, id, options.ReassignTo)
+			case options.Force:
+				archived, err := cs.CategoryRepo.ArchiveDescendants(ctx, tx, id)
+				if err != nil {
+					return err
+				}
+				log.Printf("Archived %d items with category %d", archived, id)
+			default:
+				return &models.CategoryInUseError{ItemCount: itemCount, SubcategoryCount: subcategoryCount}
+			}
+		}
+
+		return cs.CategoryRepo.Delete(ctx, tx, id)
+	})
 	if err != nil {
 		log.Printf("Failed to delete category: %v", err.Error()) // Log the error
 		return err
@@ -67,12 +131,12 @@ func (cs *CategoryService) DeleteCategory(id int) error {
 	return nil
 }
 
-func (cs *CategoryService) GetCategoryByID(id int) (*models.Category, error) {
+func (cs *CategoryService) GetCategoryByID(ctx context.Context, id int) (*models.Category, error) {
 	// Attempt to get the category by ID
 	if id <= 0 {
 		return nil, errors.New("invalid category id")
 	}
-	category, err := cs.CategoryRepo.FindByID(id)
+	category, err := cs.CategoryRepo.FindByID(ctx, cs.UoW.DB, id)
 	if err != nil {
 		log.Printf("Failed to get category by ID: %v", err.Error()) // Log the error
 		return nil, err
@@ -80,7 +144,135 @@ func (cs *CategoryService) GetCategoryByID(id int) (*models.Category, error) {
 	return category, nil
 }
 
-func (cs *CategoryService) GetAllCategories() ([]models.Category, error) {
+// GetAllCategories returns the active categories, or the deleted ones when
+// status is models.StatusDeleted.
+func (cs *CategoryService) GetAllCategories(ctx context.Context, status string) ([]models.Category, error) {
+	if status == "" {
+		status = models.StatusActive
+	}
+	if status != models.StatusActive && status != models.StatusDeleted {
+		return nil, errors.New("invalid status")
+	}
+
 	// Attempt to get all categories
-	return cs.CategoryRepo.FindAll()
+	return cs.CategoryRepo.FindAll(ctx, cs.UoW.DB, status)
+}
+
+// RestoreCategory brings back a deleted category. The items archived along
+// with it stay deleted and can be restored one by one.
+func (cs *CategoryService) RestoreCategory(ctx context.Context, id int) (*models.Category, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid category id")
+	}
+
+	var category *models.Category
+	err := cs.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		restored, err := cs.CategoryRepo.Restore(ctx, tx, id)
+		category = restored
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to restore category: %v", err.Error())
+		return nil, err
+	}
+	return category, nil
+}
+
+// GetCategoryTree returns the active categories nested under their parents,
+// with item counts and investment totals rolled up from every subcategory.
+func (cs *CategoryService) GetCategoryTree(ctx context.Context) ([]*models.CategoryNode, error) {
+	categories, err := cs.CategoryRepo.FindAll(ctx, cs.UoW.DB, models.StatusActive)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	policies, err := cs.ItemInvestmentRepo.FindAllDepreciationPolicies(ctx, cs.UoW.DB, false, now)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int]*models.CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &models.CategoryNode{Category: category, Children: []*models.CategoryNode{}}
+	}
+
+	roots := []*models.CategoryNode{}
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if parent, ok := nodes[parentIDOf(category)]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			// Top-level, or the parent has been deleted
+			roots = append(roots, node)
+		}
+	}
+
+	for _, policy := range policies {
+		bookValue := BookValueAsOf(policy, now)
+		// Add the item to its own category and every ancestor
+		for node := nodes[policy.CategoryID]; node != nil; node = nodes[parentIDOf(node.Category)] {
+			node.ItemCount++
+			node.TotalInvestment = roundMoney(node.TotalInvestment + policy.Cost)
+			node.CurrentValue = roundMoney(node.CurrentValue + bookValue)
+		}
+	}
+	return roots, nil
+}
+
+// checkParent makes sure the new parent of a category exists and is not the
+// category itself or one of its subcategories.
+func (cs *CategoryService) checkParent(ctx context.Context, tx repositories.DBTX, categoryInput models.Category) error {
+	parentID := parentIDOf(categoryInput)
+	if parentID == 0 {
+		return nil
+	}
+	if parentID == categoryInput.ID {
+		return errors.New("a category cannot be its own parent")
+	}
+
+	if err := cs.CategoryRepo.LockHierarchy(ctx, tx); err != nil {
+		return err
+	}
+	if _, err := cs.CategoryRepo.FindByID(ctx, tx, parentID); err != nil {
+		return errors.New("parent category does not exist")
+	}
+	if categoryInput.ID == 0 {
+		return nil
+	}
+
+	ancestors, err := cs.CategoryRepo.FindAncestorIDs(ctx, tx, parentID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ancestors {
+		if id == categoryInput.ID {
+			return errors.New("a category cannot be moved under one of its subcategories")
+		}
+	}
+	return nil
+}
+
+// checkReassignTarget makes sure the items of a deleted category can be moved
+// to target, which must exist and must not be one of its subcategories.
+func (cs *CategoryService) checkReassignTarget(ctx context.Context, tx repositories.DBTX, id, target int) error {
+	if _, err := cs.CategoryRepo.FindByID(ctx, tx, target); err != nil {
+		return errors.New("reassign target category does not exist")
+	}
+	ancestors, err := cs.CategoryRepo.FindAncestorIDs(ctx, tx, target)
+	if err != nil {
+		return err
+	}
+	for _, ancestorID := range ancestors {
+		if ancestorID == id {
+			return errors.New("cannot reassign items to a subcategory of the category being deleted")
+		}
+	}
+	return nil
+}
+
+func parentIDOf(category models.Category) int {
+	if category.ParentID == nil {
+		return 0
+	}
+	return *category.ParentID
 }
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+type DepreciationService struct {
+	UoW                *repositories.UnitOfWork
+	ItemInvestmentRepo repositories.ItemInvestmentRepository
+	DepreciationRepo   repositories.DepreciationRepository
+}
+
+func NewDepreciationService(uow *repositories.UnitOfWork, repo repositories.ItemInvestmentRepository, depreciationRepo repositories.DepreciationRepository) *DepreciationService {
+	return &DepreciationService{UoW: uow, ItemInvestmentRepo: repo, DepreciationRepo: depreciationRepo}
+}
+
+// GetPolicy returns the effective depreciation policy of an item
+func (s *DepreciationService) GetPolicy(ctx context.Context, itemID int) (*models.DepreciationPolicy, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	return s.ItemInvestmentRepo.FindDepreciationPolicy(ctx, s.UoW.DB, itemID)
+}
+
+// ValueAsOf returns the book value of an item on the given date
+func (s *DepreciationService) ValueAsOf(ctx context.Context, itemID int, asOf time.Time) (float64, error) {
+	policy, err := s.GetPolicy(ctx, itemID)
+	if err != nil {
+		return 0, err
+	}
+	return BookValueAsOf(*policy, asOf), nil
+}
+
+// PostPeriod posts the depreciation of every active item for the month that
+// contains period. Each item is posted in its own transaction and an item that
+// already has an entry for the month is skipped, so re-running is safe. An
+// item that fails is counted and the rest are still posted; the run then
+// returns an error so the failure is retried on the next run.
+func (s *DepreciationService) PostPeriod(ctx context.Context, period time.Time) (*models.DepreciationRun, error) {
+	periodStart, periodEnd := MonthBounds(period)
+	if periodStart.AddDate(0, 1, 0).After(time.Now()) {
+		return nil, errors.New("cannot post depreciation for a period that has not ended")
+	}
+
+	run := &models.DepreciationRun{PeriodStart: periodStart, PeriodEnd: periodEnd}
+	policies, err := s.DepreciationRepo.FindPostingCandidates(ctx, s.UoW.DB, periodEnd)
+	if err != nil {
+		log.Printf("Failed to find depreciation candidates: %v", err.Error())
+		return nil, err
+	}
+
+	for _, policy := range policies {
+		var entry *models.DepreciationEntry
+		err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+			investment, err := s.DepreciationRepo.LockInvestment(ctx, tx, policy.ItemID)
+			if err != nil {
+				return err
+			}
+			// Another run may have posted this item while we waited for the lock
+			if !investment.LastDepreciationDate.Before(periodEnd) {
+				return nil
+			}
+
+			closingValue := math.Min(BookValueAsOf(policy, periodEnd), investment.CurrentValue)
+			candidate := &models.DepreciationEntry{
+				ItemID:             policy.ItemID,
+				PeriodStart:        periodStart,
+				PeriodEnd:          periodEnd,
+				OpeningValue:       investment.CurrentValue,
+				DepreciationAmount: roundMoney(investment.CurrentValue - closingValue),
+				ClosingValue:       closingValue,
+			}
+			posted, err := s.DepreciationRepo.CreateEntry(ctx, tx, candidate)
+			if err != nil || !posted {
+				return err
+			}
+			if err := s.DepreciationRepo.UpdateInvestmentValue(ctx, tx, policy.ItemID, closingValue, periodEnd); err != nil {
+				return err
+			}
+			entry = candidate
+			return nil
+		})
+		if err != nil {
+			log.Printf("Failed to post depreciation for item %d: %v", policy.ItemID, err.Error())
+			run.Failed++
+			continue
+		}
+
+		if entry == nil {
+			run.Skipped++
+			continue
+		}
+		run.Posted++
+		run.TotalAmount = roundMoney(run.TotalAmount + entry.DepreciationAmount)
+	}
+	if run.Failed > 0 {
+		return run, fmt.Errorf("failed to post depreciation for %d items", run.Failed)
+	}
+	return run, nil
+}
+
+// MonthBounds returns the first and last day of the month containing t, as
+// UTC dates to match how DATE columns are scanned
+func MonthBounds(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, -1)
+}
+
+// AnnualDepreciation returns the depreciation charged in each year of the
+// policy's useful life. The book value never drops below the salvage value.
+func AnnualDepreciation(policy models.DepreciationPolicy) []float64 {
+	life := policy.UsefulLifeYears
+	if life <= 0 {
+		life = models.DefaultUsefulLifeYears
+	}
+	depreciable := math.Max(policy.Cost-policy.SalvageValue, 0)
+	amounts := make([]float64, life)
+
+	switch policy.Method {
+	case models.SumOfYearsDigits:
+		sum := float64(life*(life+1)) / 2
+		for year := 0; year < life; year++ {
+			amounts[year] = depreciable * float64(life-year) / sum
+		}
+	case models.DecliningBalance, models.DoubleDeclining:
+		rate := 2 / float64(life)
+		if policy.Method == models.DecliningBalance {
+			rate = policy.Rate / 100
+			if rate <= 0 {
+				rate = 1 / float64(life)
+			}
+		}
+
+		bookValue := policy.Cost
+		for year := 0; year < life; year++ {
+			remaining := bookValue - policy.SalvageValue
+			amount := bookValue * rate
+			// Switch to straight-line once it depreciates faster, so the
+			// asset reaches its salvage value at the end of its life
+			if straightLine := remaining / float64(life-year); straightLine > amount {
+				amount = straightLine
+			}
+			amount = math.Max(math.Min(amount, remaining), 0)
+			amounts[year] = amount
+			bookValue -= amount
+		}
+	default:
+		for year := 0; year < life; year++ {
+			amounts[year] = depreciable / float64(life)
+		}
+	}
+	return amounts
+}
+
+// BookValueAsOf returns the value of an asset on asOf. Depreciation is
+// counted in whole months since the purchase date and spread evenly over
+// the months of each year of use.
+func BookValueAsOf(policy models.DepreciationPolicy, asOf time.Time) float64 {
+	months := monthsBetween(policy.PurchaseDate, asOf)
+	if months <= 0 {
+		return roundMoney(policy.Cost)
+	}
+
+	return valueAfterMonths(policy, AnnualDepreciation(policy), months)
+}
+
+// DepreciationSchedule projects an item's depreciation from its purchase date,
+// one row per month or year, until the salvage value or end of life is reached.
+func DepreciationSchedule(policy models.DepreciationPolicy, granularity string) []models.DepreciationScheduleRow {
+	step := 12
+	if granularity == models.ScheduleMonthly {
+		step = 1
+	}
+
+	annual := AnnualDepreciation(policy)
+	lifeMonths := len(annual) * 12
+	floor := roundMoney(math.Min(policy.SalvageValue, policy.Cost))
+
+	var rows []models.DepreciationScheduleRow
+	opening := roundMoney(policy.Cost)
+	for elapsed := step; elapsed-step < lifeMonths && opening > floor; elapsed += step {
+		closing := valueAfterMonths(policy, annual, elapsed)
+		periodStart := policy.PurchaseDate.AddDate(0, elapsed-step, 0)
+		rows = append(rows, models.DepreciationScheduleRow{
+			Period:                  len(rows) + 1,
+			PeriodStart:             periodStart,
+			PeriodEnd:               policy.PurchaseDate.AddDate(0, elapsed, -1),
+			OpeningValue:            opening,
+			DepreciationAmount:      roundMoney(opening - closing),
+			AccumulatedDepreciation: roundMoney(policy.Cost - closing),
+			ClosingValue:            closing,
+		})
+		opening = closing
+	}
+	return rows
+}
+
+// valueAfterMonths returns the book value once the given number of months
+// of the annual depreciation amounts have been charged.
+func valueAfterMonths(policy models.DepreciationPolicy, annual []float64, months int) float64 {
+	value := policy.Cost
+	for year, amount := range annual {
+		elapsed := months - year*12
+		if elapsed <= 0 {
+			break
+		}
+		if elapsed >= 12 {
+			value -= amount
+			continue
+		}
+		value -= amount * float64(elapsed) / 12
+	}
+	return roundMoney(math.Max(value, math.Min(policy.SalvageValue, policy.Cost)))
+}
+
+// monthsBetween counts the whole months elapsed from start to end
+func monthsBetween(start, end time.Time) int {
+	if start.IsZero() || !end.After(start) {
+		return 0
+	}
+	months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+	if end.Day() < start.Day() {
+		months--
+	}
+	return months
+}
+
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
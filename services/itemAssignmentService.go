@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+var (
+	ErrItemCheckedOut    = errors.New("item is already checked out")
+	ErrItemNotCheckedOut = errors.New("item is not checked out")
+	ErrItemReserved      = errors.New("item is reserved by someone else during the check-out")
+)
+
+// ItemAssignmentService lends items out through ItemService, so checking an
+// item out or in keeps its lifecycle state in step
+type ItemAssignmentService struct {
+	UoW             *repositories.UnitOfWork
+	ItemService     *ItemService
+	AssignmentRepo  repositories.ItemAssignmentRepository
+	ReservationRepo repositories.ItemReservationRepository
+}
+
+func NewItemAssignmentService(uow *repositories.UnitOfWork, itemService *ItemService, assignmentRepo repositories.ItemAssignmentRepository,
+	reservationRepo repositories.ItemReservationRepository) *ItemAssignmentService {
+	return &ItemAssignmentService{UoW: uow, ItemService: itemService, AssignmentRepo: assignmentRepo, ReservationRepo: reservationRepo}
+}
+
+// Checkout lends an item to request.AssigneeID, or to userID when no assignee
+// is given, and moves an item in stock to in use. The item row is locked
+// first, so concurrent check-outs of the same item are serialized and only
+// the first one succeeds. A check-out may not run into a reservation of
+// anyone but the assignee; without a due date it lasts indefinitely.
+func (s *ItemAssignmentService) Checkout(ctx context.Context, itemID, userID int, request models.CheckoutRequest) (*models.ItemAssignment, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	assignment := &models.ItemAssignment{
+		ItemID:            itemID,
+		AssigneeID:        request.AssigneeID,
+		CheckedOutBy:      userID,
+		CheckoutCondition: strings.TrimSpace(request.Condition),
+	}
+	if assignment.AssigneeID == 0 {
+		assignment.AssigneeID = userID
+	}
+	if assignment.AssigneeID < 0 {
+		return nil, errors.New("invalid assignee id")
+	}
+	if request.DueDate != "" {
+		const dateLayout = "2006-01-02"
+		dueDate, err := time.Parse(dateLayout, request.DueDate)
+		if err != nil {
+			return nil, errors.New("invalid due date format, please use YYYY-MM-DD")
+		}
+		if dueDate.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+			return nil, errors.New("due date cannot be in the past")
+		}
+		assignment.DueDate = &dueDate
+	}
+
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		state, err := s.ItemService.ItemRepo.LockLifecycle(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+		if !state.IsAvailable() {
+			return fmt.Errorf("item is %s and cannot be checked out", state)
+		}
+		open, err := s.AssignmentRepo.FindOpenByItemID(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+		if open != nil {
+			return ErrItemCheckedOut
+		}
+
+		// The item is due back by the end of its due date
+		var until time.Time
+		if assignment.DueDate != nil {
+			until = assignment.DueDate.AddDate(0, 0, 1)
+		}
+		reservations, err := s.ReservationRepo.FindOverlapping(ctx, tx, itemID, time.Now(), until)
+		if err != nil {
+			return err
+		}
+		for _, reservation := range reservations {
+			if reservation.UserID != assignment.AssigneeID {
+				return fmt.Errorf("%w: reservation #%d starts %s", ErrItemReserved, reservation.ID, reservation.StartsAt.Format(time.RFC3339))
+			}
+		}
+
+		if err := s.AssignmentRepo.Create(ctx, tx, assignment); err != nil {
+			return err
+		}
+		reason := fmt.Sprintf("Checked out to user #%d", assignment.AssigneeID)
+		return s.ItemService.syncAssignmentState(ctx, tx, itemID, userID, true, reason)
+	})
+	if err != nil {
+		log.Printf("Failed to check out item %d: %v", itemID, err.Error())
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// Checkin closes the open assignment of an item and moves it from in use
+// back to in stock
+func (s *ItemAssignmentService) Checkin(ctx context.Context, itemID, userID int, request models.CheckinRequest) (*models.ItemAssignment, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+
+	var assignment *models.ItemAssignment
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if err := s.ItemService.ItemRepo.Lock(ctx, tx, itemID); err != nil {
+			return err
+		}
+		open, err := s.AssignmentRepo.FindOpenByItemID(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+		if open == nil {
+			return ErrItemNotCheckedOut
+		}
+
+		open.CheckedInBy = userID
+		open.ReturnCondition = strings.TrimSpace(request.Condition)
+		if err := s.AssignmentRepo.Close(ctx, tx, open); err != nil {
+			return err
+		}
+		assignment = open
+		return s.ItemService.syncAssignmentState(ctx, tx, itemID, userID, false, "Checked in")
+	})
+	if err != nil {
+		log.Printf("Failed to check in item %d: %v", itemID, err.Error())
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// GetAssignedItems returns the items currently checked out to a user
+func (s *ItemAssignmentService) GetAssignedItems(ctx context.Context, userID int) ([]models.ItemAssignment, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid user id")
+	}
+	return s.AssignmentRepo.FindOpen(ctx, s.UoW.DB, userID, false)
+}
+
+// GetOverdueItems returns every open assignment past its due date
+func (s *ItemAssignmentService) GetOverdueItems(ctx context.Context) ([]models.ItemAssignment, error) {
+	return s.AssignmentRepo.FindOpen(ctx, s.UoW.DB, 0, true)
+}
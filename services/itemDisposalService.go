@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+// ItemDisposalService disposes of items through ItemService, so a disposal
+// follows the same lifecycle rules as any other transition
+type ItemDisposalService struct {
+	UoW              *repositories.UnitOfWork
+	ItemService      *ItemService
+	DepreciationRepo repositories.DepreciationRepository
+	DisposalRepo     repositories.ItemDisposalRepository
+}
+
+func NewItemDisposalService(uow *repositories.UnitOfWork, itemService *ItemService, depreciationRepo repositories.DepreciationRepository,
+	disposalRepo repositories.ItemDisposalRepository) *ItemDisposalService {
+	return &ItemDisposalService{UoW: uow, ItemService: itemService, DepreciationRepo: depreciationRepo, DisposalRepo: disposalRepo}
+}
+
+// DisposeItem records an item being sold, scrapped or donated, approved by
+// userID, and moves it to the disposed state. The gain or loss is the
+// proceeds less the book value in item_investments, which stops depreciating
+// from then on. Only retired or lost items can be disposed of.
+func (s *ItemDisposalService) DisposeItem(ctx context.Context, itemID, userID int, disposal models.ItemDisposal) (*models.ItemDisposal, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	if !disposal.Method.IsValid() {
+		return nil, errors.New("disposal method must be sold, scrapped or donated")
+	}
+	if disposal.Proceeds < 0 {
+		return nil, errors.New("proceeds cannot be negative")
+	}
+	if disposal.Method == models.DisposalDonated && disposal.Proceeds != 0 {
+		return nil, errors.New("a donated item cannot have proceeds")
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if disposal.DisposedOn.IsZero() {
+		disposal.DisposedOn = today
+	}
+	if disposal.DisposedOn.After(today) {
+		return nil, errors.New("disposal date cannot be in the future")
+	}
+	disposal.ItemID = itemID
+	disposal.ApprovedBy = userID
+	disposal.Notes = strings.TrimSpace(disposal.Notes)
+
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		reason := fmt.Sprintf("Disposed of (%s)", disposal.Method)
+		if disposal.Notes != "" {
+			reason += ": " + disposal.Notes
+		}
+		request := models.TransitionRequest{State: models.LifecycleDisposed, Reason: reason}
+		if _, err := s.ItemService.transitionItem(ctx, tx, itemID, userID, request); err != nil {
+			return err
+		}
+
+		item, err := s.ItemService.ItemRepo.FindByID(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return errors.New("item does not exist")
+		}
+		if disposal.DisposedOn.Before(item.PurchaseDate) {
+			return errors.New("disposal date cannot be before the purchase date")
+		}
+		investment, err := s.DepreciationRepo.LockInvestment(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+
+		disposal.ItemName = item.Name
+		disposal.CategoryName = item.CategoryName
+		disposal.BookValue = roundMoney(investment.CurrentValue)
+		disposal.GainLoss = roundMoney(disposal.Proceeds - disposal.BookValue)
+		return s.DisposalRepo.Create(ctx, tx, &disposal)
+	})
+	if err != nil {
+		log.Printf("Failed to dispose of item %d: %v", itemID, err.Error())
+		return nil, err
+	}
+	return &disposal, nil
+}
+
+// GetDisposalReport lists the disposals dated from from to to, both
+// included, with their totals
+func (s *ItemDisposalService) GetDisposalReport(ctx context.Context, from, to time.Time) (*models.DisposalReport, error) {
+	if to.Before(from) {
+		return nil, errors.New("to cannot be before from")
+	}
+
+	disposals, err := s.DisposalRepo.FindByPeriod(ctx, s.UoW.DB, from, to)
+	if err != nil {
+		log.Printf("Failed to get disposals: %v", err.Error())
+		return nil, err
+	}
+
+	report := &models.DisposalReport{From: from, To: to, Disposals: disposals}
+	for _, disposal := range disposals {
+		report.Totals.Count++
+		report.Totals.BookValue = roundMoney(report.Totals.BookValue + disposal.BookValue)
+		report.Totals.Proceeds = roundMoney(report.Totals.Proceeds + disposal.Proceeds)
+		report.Totals.GainLoss = roundMoney(report.Totals.GainLoss + disposal.GainLoss)
+	}
+	return report, nil
+}
@@ -1,22 +1,132 @@
 package services
 
 import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
 )
 
 type ItemInvestmentService struct {
+	UoW                *repositories.UnitOfWork
 	ItemInvestmentRepo repositories.ItemInvestmentRepository
 }
 
-func NewItemInvestmentService(repo repositories.ItemInvestmentRepository) *ItemInvestmentService {
-	return &ItemInvestmentService{ItemInvestmentRepo: repo}
+func NewItemInvestmentService(uow *repositories.UnitOfWork, repo repositories.ItemInvestmentRepository) *ItemInvestmentService {
+	return &ItemInvestmentService{UoW: uow, ItemInvestmentRepo: repo}
+}
+
+// GetByItemID returns an item's investment, as last posted, with today's
+// book value and its projected depreciation schedule. When asOf is set the book value and
+// accumulated depreciation on that date are included as well. A disposed
+// item keeps the book value it was disposed of at.
+func (s *ItemInvestmentService) GetByItemID(ctx context.Context, itemId int, asOf *time.Time, granularity string) (models.ItemInvestment, error) {
+	itemInvestment, err := s.ItemInvestmentRepo.FindByItemId(ctx, s.UoW.DB, itemId)
+	if err != nil || itemInvestment.ItemID == 0 {
+		return itemInvestment, err
+	}
+
+	// CurrentValue stays as posted, so it matches the totals and disposals
+	policy, err := s.ItemInvestmentRepo.FindDepreciationPolicy(ctx, s.UoW.DB, itemId)
+	if err != nil {
+		return itemInvestment, err
+	}
+	frozenValue := itemInvestment.CurrentValue
+	itemInvestment.BookValueToday = frozenValue
+	if itemInvestment.DisposedOn == nil {
+		itemInvestment.BookValueToday = BookValueAsOf(*policy, time.Now())
+	}
+	itemInvestment.Schedule = DepreciationSchedule(*policy, granularity)
+
+	if asOf != nil {
+		bookValue := BookValueAsOf(*policy, *asOf)
+		if itemInvestment.DisposedOn != nil && !asOf.Before(*itemInvestment.DisposedOn) {
+			bookValue = frozenValue
+		}
+		accumulated := roundMoney(policy.Cost - bookValue)
+		itemInvestment.AsOf = asOf
+		itemInvestment.BookValueAsOf = &bookValue
+		itemInvestment.AccumulatedDepreciation = &accumulated
+	}
+	return itemInvestment, nil
+}
+
+func (s *ItemInvestmentService) CountAllItemInvestments(ctx context.Context) (*models.ItemInvestment, error) {
+	return s.ItemInvestmentRepo.CountAll(ctx, s.UoW.DB)
+}
+
+// SummarizeInvestments groups items by category, purchase year or age bucket
+// and values them as of asOf. Items bought after asOf, or disposed of by
+// then, are left out.
+func (s *ItemInvestmentService) SummarizeInvestments(ctx context.Context, groupBy string, asOf time.Time, includeDeleted bool) (*models.InvestmentSummary, error) {
+	policies, err := s.ItemInvestmentRepo.FindAllDepreciationPolicies(ctx, s.UoW.DB, includeDeleted, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.InvestmentSummary{AsOf: asOf, GroupBy: groupBy, IncludeDeleted: includeDeleted, Groups: []models.InvestmentSummaryGroup{}}
+	summary.Totals.Group = "total"
+	groupIndex := make(map[string]int)
+	for _, policy := range policies {
+		if policy.PurchaseDate.After(asOf) {
+			continue
+		}
+
+		key := investmentGroupKey(policy, groupBy, asOf)
+		index, ok := groupIndex[key]
+		if !ok {
+			index = len(summary.Groups)
+			groupIndex[key] = index
+			group := models.InvestmentSummaryGroup{Group: key}
+			// Categories are told apart by id, as names repeat under different parents
+			if groupBy == models.GroupByCategory {
+				group.Group = policy.CategoryName
+				group.CategoryID = policy.CategoryID
+			}
+			summary.Groups = append(summary.Groups, group)
+		}
+
+		bookValue := BookValueAsOf(policy, asOf)
+		addToSummaryGroup(&summary.Groups[index], policy.Cost, bookValue)
+		addToSummaryGroup(&summary.Totals, policy.Cost, bookValue)
+	}
+
+	sort.Slice(summary.Groups, func(a, b int) bool {
+		if summary.Groups[a].Group != summary.Groups[b].Group {
+			return summary.Groups[a].Group < summary.Groups[b].Group
+		}
+		return summary.Groups[a].CategoryID < summary.Groups[b].CategoryID
+	})
+	return summary, nil
 }
 
-func (s *ItemInvestmentService) GetByItemID(itemId int) (models.ItemInvestment, error) {
-	return s.ItemInvestmentRepo.FindByItemId(itemId)
+func addToSummaryGroup(group *models.InvestmentSummaryGroup, cost, bookValue float64) {
+	group.ItemCount++
+	group.AcquisitionCost = roundMoney(group.AcquisitionCost + cost)
+	group.CurrentValue = roundMoney(group.CurrentValue + bookValue)
+	group.AccumulatedDepreciation = roundMoney(group.AcquisitionCost - group.CurrentValue)
 }
 
-func (s *ItemInvestmentService) CountAllItemInvestments() (*models.ItemInvestment, error) {
-	return s.ItemInvestmentRepo.CountAll()
+func investmentGroupKey(policy models.DepreciationPolicy, groupBy string, asOf time.Time) string {
+	switch groupBy {
+	case models.GroupByPurchaseYear:
+		return strconv.Itoa(policy.PurchaseDate.Year())
+	case models.GroupByAgeBucket:
+		years := monthsBetween(policy.PurchaseDate, asOf) / 12
+		switch {
+		case years < 1:
+			return "0-1 years"
+		case years < 3:
+			return "1-3 years"
+		case years < 5:
+			return "3-5 years"
+		default:
+			return "5+ years"
+		}
+	default:
+		return strconv.Itoa(policy.CategoryID)
+	}
 }
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+// ErrInvalidTransition is returned for a state change the lifecycle does not allow
+var ErrInvalidTransition = errors.New("invalid lifecycle transition")
+
+// lifecycleTransitions lists the states each lifecycle state can move to.
+// Disposed is final.
+var lifecycleTransitions = map[models.LifecycleState][]models.LifecycleState{
+	models.LifecycleRequested:   {models.LifecycleOrdered, models.LifecycleRetired},
+	models.LifecycleOrdered:     {models.LifecycleInStock, models.LifecycleRetired},
+	models.LifecycleInStock:     {models.LifecycleInUse, models.LifecycleUnderRepair, models.LifecycleLost, models.LifecycleRetired},
+	models.LifecycleInUse:       {models.LifecycleInStock, models.LifecycleUnderRepair, models.LifecycleLost, models.LifecycleRetired},
+	models.LifecycleUnderRepair: {models.LifecycleInStock, models.LifecycleInUse, models.LifecycleRetired},
+	models.LifecycleLost:        {models.LifecycleInStock, models.LifecycleRetired, models.LifecycleDisposed},
+	models.LifecycleRetired:     {models.LifecycleInStock, models.LifecycleDisposed},
+}
+
+// initialLifecycleStates are the states an item can be created in
+var initialLifecycleStates = []models.LifecycleState{models.LifecycleRequested, models.LifecycleOrdered, models.LifecycleInStock}
+
+func isInitialLifecycleState(state models.LifecycleState) bool {
+	for _, initial := range initialLifecycleStates {
+		if state == initial {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions returns the states an item in the given state can move to
+func AllowedTransitions(from models.LifecycleState) []models.LifecycleState {
+	return lifecycleTransitions[from]
+}
+
+// CanTransition reports whether the lifecycle allows moving from one state to another
+func CanTransition(from, to models.LifecycleState) bool {
+	for _, state := range lifecycleTransitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionItem moves an item to another lifecycle state on behalf of
+// userID and records the change. The item is locked while it changes, so
+// concurrent transitions are checked against the state the other one left.
+func (s *ItemService) TransitionItem(ctx context.Context, itemID, userID int, request models.TransitionRequest) (*models.ItemTransition, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	if !request.State.IsValid() {
+		return nil, errors.New("invalid lifecycle state")
+	}
+	// Disposing of an item needs a disposal record, see ItemDisposalService
+	if request.State == models.LifecycleDisposed {
+		return nil, fmt.Errorf("%w: use the dispose endpoint to dispose of an item", ErrInvalidTransition)
+	}
+	request.Reason = strings.TrimSpace(request.Reason)
+	if request.Reason == "" {
+		return nil, errors.New("reason is required")
+	}
+
+	var transition *models.ItemTransition
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		var err error
+		transition, err = s.transitionItem(ctx, tx, itemID, userID, request)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to transition item %d: %v", itemID, err.Error())
+		return nil, err
+	}
+	return transition, nil
+}
+
+// transitionItem does the work of TransitionItem inside the caller's transaction
+func (s *ItemService) transitionItem(ctx context.Context, tx repositories.DBTX, itemID, userID int, request models.TransitionRequest) (*models.ItemTransition, error) {
+	from, err := s.ItemRepo.LockLifecycle(ctx, tx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if !CanTransition(from, request.State) {
+		return nil, fmt.Errorf("%w: an item that is %s cannot become %s", ErrInvalidTransition, from, request.State)
+	}
+	if err := s.ItemRepo.UpdateLifecycleState(ctx, tx, itemID, request.State); err != nil {
+		return nil, err
+	}
+
+	transition := &models.ItemTransition{
+		ItemID:         itemID,
+		FromState:      from,
+		ToState:        request.State,
+		Reason:         request.Reason,
+		TransitionedBy: userID,
+	}
+	if err := s.TransitionRepo.Create(ctx, tx, transition); err != nil {
+		return nil, err
+	}
+	return transition, nil
+}
+
+// syncAssignmentState moves an item from in stock to in use when it is
+// checked out, or back when it is checked in, inside the caller's
+// transaction. Items in any other state are left as they are.
+func (s *ItemService) syncAssignmentState(ctx context.Context, tx repositories.DBTX, itemID, userID int, checkedOut bool, reason string) error {
+	from, to := models.LifecycleInStock, models.LifecycleInUse
+	if !checkedOut {
+		from, to = to, from
+	}
+	state, err := s.ItemRepo.LockLifecycle(ctx, tx, itemID)
+	if err != nil {
+		return err
+	}
+	if state != from {
+		return nil
+	}
+	_, err = s.transitionItem(ctx, tx, itemID, userID, models.TransitionRequest{State: to, Reason: reason})
+	return err
+}
+
+// GetTransitions returns the lifecycle history of an item, oldest first
+func (s *ItemService) GetTransitions(ctx context.Context, itemID int) ([]models.ItemTransition, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	return s.TransitionRepo.FindByItemID(ctx, s.UoW.DB, itemID)
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+type ItemMovementService struct {
+	UoW          *repositories.UnitOfWork
+	ItemRepo     repositories.ItemRepository
+	LocationRepo repositories.LocationRepository
+	MovementRepo repositories.ItemMovementRepository
+}
+
+func NewItemMovementService(uow *repositories.UnitOfWork, itemRepo repositories.ItemRepository, locationRepo repositories.LocationRepository, movementRepo repositories.ItemMovementRepository) *ItemMovementService {
+	return &ItemMovementService{UoW: uow, ItemRepo: itemRepo, LocationRepo: locationRepo, MovementRepo: movementRepo}
+}
+
+// MoveItem moves an item to another location on behalf of userID. The new
+// location and the movement record are written in one transaction, with the
+// item locked, so the history always matches the current location.
+func (s *ItemMovementService) MoveItem(ctx context.Context, itemID, userID int, request models.MoveItemRequest) (*models.ItemMovement, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	if request.LocationID <= 0 {
+		return nil, errors.New("location id is required")
+	}
+	request.Reason = strings.TrimSpace(request.Reason)
+	if request.Reason == "" {
+		return nil, errors.New("reason is required")
+	}
+
+	var movement *models.ItemMovement
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		fromLocationID, err := s.ItemRepo.LockLocation(ctx, tx, itemID)
+		if err != nil {
+			return err
+		}
+		if fromLocationID == request.LocationID {
+			return errors.New("item is already at this location")
+		}
+		location, err := s.LocationRepo.FindByID(ctx, tx, request.LocationID)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.ItemRepo.Update(ctx, tx, &models.Item{ID: itemID, LocationID: request.LocationID}); err != nil {
+			return err
+		}
+		movement = &models.ItemMovement{
+			ItemID:         itemID,
+			FromLocationID: fromLocationID,
+			ToLocationID:   location.ID,
+			ToLocationPath: location.Path,
+			MovedBy:        userID,
+			Reason:         request.Reason,
+		}
+		return s.MovementRepo.Create(ctx, tx, movement)
+	})
+	if err != nil {
+		log.Printf("Failed to move item %d: %v", itemID, err.Error())
+		return nil, err
+	}
+	return movement, nil
+}
+
+// GetMovements returns the movement timeline of an item, oldest first
+func (s *ItemMovementService) GetMovements(ctx context.Context, itemID int) ([]models.ItemMovement, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	return s.MovementRepo.FindByItemID(ctx, s.UoW.DB, itemID)
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+// ErrReservationConflict is returned when a booking overlaps another one
+var ErrReservationConflict = repositories.ErrReservationConflict
+
+type ItemReservationService struct {
+	UoW             *repositories.UnitOfWork
+	ItemService     *ItemService
+	AssignmentRepo  repositories.ItemAssignmentRepository
+	ReservationRepo repositories.ItemReservationRepository
+}
+
+func NewItemReservationService(uow *repositories.UnitOfWork, itemService *ItemService, assignmentRepo repositories.ItemAssignmentRepository, reservationRepo repositories.ItemReservationRepository) *ItemReservationService {
+	return &ItemReservationService{UoW: uow, ItemService: itemService, AssignmentRepo: assignmentRepo, ReservationRepo: reservationRepo}
+}
+
+// Book reserves an item for userID. Overlapping bookings are rejected by the
+// database with ErrReservationConflict, so two concurrent requests for the
+// same slot cannot both succeed.
+func (s *ItemReservationService) Book(ctx context.Context, itemID, userID int, request models.ReservationRequest) (*models.ItemReservation, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	if request.StartsAt.IsZero() || request.EndsAt.IsZero() {
+		return nil, errors.New("starts_at and ends_at are required")
+	}
+	if !request.EndsAt.After(request.StartsAt) {
+		return nil, errors.New("ends_at must be after starts_at")
+	}
+	if request.EndsAt.Before(time.Now()) {
+		return nil, errors.New("cannot book a period that has already ended")
+	}
+
+	reservation := &models.ItemReservation{
+		ItemID:   itemID,
+		UserID:   userID,
+		StartsAt: request.StartsAt,
+		EndsAt:   request.EndsAt,
+		Purpose:  strings.TrimSpace(request.Purpose),
+	}
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if err := s.ItemService.ItemRepo.Lock(ctx, tx, itemID); err != nil {
+			return err
+		}
+		return s.ReservationRepo.Create(ctx, tx, reservation)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// Cancel cancels a booked reservation. Only its owner or an admin may cancel
+// it, and a reservation already handed off must be checked in instead.
+func (s *ItemReservationService) Cancel(ctx context.Context, reservationID int, session models.Session) (*models.ItemReservation, error) {
+	if reservationID <= 0 {
+		return nil, errors.New("invalid reservation id")
+	}
+
+	var reservation *models.ItemReservation
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		locked, err := s.ReservationRepo.Lock(ctx, tx, reservationID)
+		if err != nil {
+			return err
+		}
+		if locked.UserID != session.UserID && session.Role != models.RoleAdmin {
+			return errors.New("only the owner of a reservation can cancel it")
+		}
+		if locked.Status != models.ReservationBooked {
+			return errors.New("only booked reservations can be cancelled")
+		}
+
+		locked.Status = models.ReservationCancelled
+		if err := s.ReservationRepo.UpdateStatus(ctx, tx, locked); err != nil {
+			return err
+		}
+		reservation = locked
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// GetAvailability reports whether an item is free from from until to
+func (s *ItemReservationService) GetAvailability(ctx context.Context, itemID int, from, to time.Time) (*models.ItemAvailability, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	if !to.After(from) {
+		return nil, errors.New("to must be after from")
+	}
+
+	reservations, err := s.ReservationRepo.FindOverlapping(ctx, s.UoW.DB, itemID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	assignment, err := s.AssignmentRepo.FindOpenByItemID(ctx, s.UoW.DB, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := &models.ItemAvailability{ItemID: itemID, From: from, To: to, Reservations: reservations}
+	// A check-out only gets in the way if it is not due back before the
+	// window, and an item is due back by the end of its due date
+	if assignment != nil && (assignment.DueDate == nil || assignment.DueDate.AddDate(0, 0, 1).After(from)) {
+		availability.CheckedOut = assignment
+	}
+	availability.Available = len(reservations) == 0 && availability.CheckedOut == nil
+	return availability, nil
+}
+
+// GetUpcomingReservations returns the reservations of a user that have not ended
+func (s *ItemReservationService) GetUpcomingReservations(ctx context.Context, userID int) ([]models.ItemReservation, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid user id")
+	}
+	return s.ReservationRepo.FindUpcomingByUser(ctx, s.UoW.DB, userID)
+}
+
+// StartReservations hands every reservation that has begun off to the
+// check-out flow, checking the item out to the person who booked it until
+// the reservation ends. A reservation whose item is still out with someone
+// else, or cannot be lent out in its current lifecycle state, is retried on
+// the next run and marked missed once it has ended.
+func (s *ItemReservationService) StartReservations(ctx context.Context, now time.Time) (int, int, error) {
+	started, err := s.ReservationRepo.FindStarted(ctx, s.UoW.DB, now)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	handedOff, missed := 0, 0
+	for _, candidate := range started {
+		var outcome string
+		err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+			reservation, err := s.ReservationRepo.Lock(ctx, tx, candidate.ID)
+			if err != nil {
+				return err
+			}
+			// Cancelled or handed off since it was listed
+			if reservation.Status != models.ReservationBooked {
+				return nil
+			}
+			state, err := s.ItemService.ItemRepo.LockLifecycle(ctx, tx, reservation.ItemID)
+			if err != nil {
+				return err
+			}
+
+			open, err := s.AssignmentRepo.FindOpenByItemID(ctx, tx, reservation.ItemID)
+			if err != nil {
+				return err
+			}
+			switch {
+			case !reservation.EndsAt.After(now):
+				reservation.Status = models.ReservationMissed
+				outcome = reservation.Status
+				return s.ReservationRepo.UpdateStatus(ctx, tx, reservation)
+			case open != nil && open.AssigneeID == reservation.UserID:
+				// Already checked out to the same person by hand
+				reservation.AssignmentID = open.ID
+			case open != nil:
+				// Still out with someone else, try again on the next run
+				return nil
+			case !state.IsAvailable():
+				// Under repair, lost or retired, try again on the next run
+				return nil
+			default:
+				endsAt := reservation.EndsAt.UTC()
+				dueDate := time.Date(endsAt.Year(), endsAt.Month(), endsAt.Day(), 0, 0, 0, 0, time.UTC)
+				assignment := &models.ItemAssignment{
+					ItemID:            reservation.ItemID,
+					AssigneeID:        reservation.UserID,
+					DueDate:           &dueDate,
+					CheckoutCondition: fmt.Sprintf("Checked out for reservation #%d", reservation.ID),
+				}
+				if err := s.AssignmentRepo.Create(ctx, tx, assignment); err != nil {
+					return err
+				}
+				reason := fmt.Sprintf("Checked out to user #%d for reservation #%d", reservation.UserID, reservation.ID)
+				if err := s.ItemService.syncAssignmentState(ctx, tx, reservation.ItemID, 0, true, reason); err != nil {
+					return err
+				}
+				reservation.AssignmentID = assignment.ID
+			}
+
+			reservation.Status = models.ReservationCheckedOut
+			outcome = reservation.Status
+			return s.ReservationRepo.UpdateStatus(ctx, tx, reservation)
+		})
+		if err != nil {
+			log.Printf("Failed to start reservation %d: %v", candidate.ID, err.Error())
+			continue
+		}
+
+		switch outcome {
+		case models.ReservationCheckedOut:
+			handedOff++
+		case models.ReservationMissed:
+			missed++
+		}
+	}
+	return handedOff, missed, nil
+}
@@ -1,38 +1,176 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/notifications"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/validations"
 )
 
 type ItemService struct {
-	ItemRepo repositories.ItemRepository
+	UoW                *repositories.UnitOfWork
+	ItemRepo           repositories.ItemRepository
+	ItemInvestmentRepo repositories.ItemInvestmentRepository
+	LocationRepo       repositories.LocationRepository
+	PlanRepo           repositories.MaintenancePlanRepository
+	TransitionRepo     repositories.ItemTransitionRepository
+	AssignmentRepo     repositories.ItemAssignmentRepository
 }
 
-func NewItemService(repo repositories.ItemRepository) *ItemService {
-	return &ItemService{ItemRepo: repo}
+// ErrDuplicateSerialNumber is returned when another item has the serial number
+var ErrDuplicateSerialNumber = repositories.ErrDuplicateSerialNumber
+
+// ErrDepreciationPosted is returned when changing how an item depreciates
+// after depreciation has been posted for it
+var ErrDepreciationPosted = errors.New("the price, purchase date and depreciation settings cannot change once depreciation has been posted")
+
+func NewItemService(uow *repositories.UnitOfWork, repo repositories.ItemRepository, investmentRepo repositories.ItemInvestmentRepository, locationRepo repositories.LocationRepository,
+	planRepo repositories.MaintenancePlanRepository, transitionRepo repositories.ItemTransitionRepository, assignmentRepo repositories.ItemAssignmentRepository) *ItemService {
+	return &ItemService{UoW: uow, ItemRepo: repo, ItemInvestmentRepo: investmentRepo, LocationRepo: locationRepo, PlanRepo: planRepo, TransitionRepo: transitionRepo,
+		AssignmentRepo: assignmentRepo}
 }
 
-func (s *ItemService) CreateItem(itemInput models.Item) (*models.Item, error) {
+func (s *ItemService) CreateItem(ctx context.Context, userID int, itemInput models.Item) (*models.Item, error) {
 	err := validations.ValidateItemInput(itemInput)
 	if err != nil {
 		return nil, err
 	}
+	if itemInput.LifecycleState == "" {
+		itemInput.LifecycleState = models.LifecycleInStock
+	}
+	if !isInitialLifecycleState(itemInput.LifecycleState) {
+		return nil, errors.New("a new item must be requested, ordered or in stock")
+	}
+
+	// The item, its investment row and the start of its lifecycle history
+	// must commit or roll back together
+	var item *models.Item
+	err = s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if itemInput.LocationID != 0 {
+			if _, err := s.LocationRepo.FindByID(ctx, tx, itemInput.LocationID); err != nil {
+				return err
+			}
+		}
+		assetTag, err := s.ItemRepo.NextAssetTag(ctx, tx, itemInput.CategoryID)
+		if err != nil {
+			return err
+		}
+		itemInput.AssetTag = assetTag
+		created, err := s.ItemRepo.Create(ctx, tx, &itemInput)
+		if err != nil {
+			return err
+		}
+		policy, err := s.ItemInvestmentRepo.FindDepreciationPolicy(ctx, tx, created.ID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		investment := models.ItemInvestment{
+			ItemID:               created.ID,
+			InitialPrice:         policy.Cost,
+			CurrentValue:         BookValueAsOf(*policy, now),
+			LastDepreciationDate: now,
+		}
+		if err := s.ItemInvestmentRepo.Create(ctx, tx, &investment); err != nil {
+			return err
+		}
 
-	return s.ItemRepo.Create(&itemInput)
+		transition := &models.ItemTransition{
+			ItemID:         created.ID,
+			ToState:        itemInput.LifecycleState,
+			Reason:         "Created",
+			TransitionedBy: userID,
+		}
+		if err := s.TransitionRepo.Create(ctx, tx, transition); err != nil {
+			return err
+		}
+		item = created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
 }
 
-func (s *ItemService) GetItemsByID(id int) (*models.Item, error) {
+func (s *ItemService) GetItemsByID(ctx context.Context, id int) (*models.Item, error) {
 	if id == 0 {
 		return nil, errors.New("invalid id")
 	}
-	return s.ItemRepo.FindByID(id)
+	return s.ItemRepo.FindByID(ctx, s.UoW.DB, id)
+}
+
+// LookupItem finds the active item with a scanned asset tag or serial number,
+// exactly one of which must be given, along with its current assignment and
+// location. It returns nil when no item matches.
+func (s *ItemService) LookupItem(ctx context.Context, assetTag, serialNumber string) (*models.ItemLookup, error) {
+	assetTag = strings.ToUpper(strings.TrimSpace(assetTag))
+	serialNumber = strings.TrimSpace(serialNumber)
+	if (assetTag == "") == (serialNumber == "") {
+		return nil, errors.New("either a tag or a serial number is required")
+	}
+
+	id, err := s.ItemRepo.FindIDByIdentifier(ctx, s.UoW.DB, assetTag, serialNumber)
+	if err != nil || id == 0 {
+		return nil, err
+	}
+	item, err := s.ItemRepo.FindByID(ctx, s.UoW.DB, id)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	lookup := &models.ItemLookup{Item: *item}
+	lookup.CurrentAssignment, err = s.AssignmentRepo.FindOpenByItemID(ctx, s.UoW.DB, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.LocationID != 0 {
+		lookup.Location, err = s.LocationRepo.FindByID(ctx, s.UoW.DB, item.LocationID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lookup, nil
+}
+
+// maxLabelItems caps a label sheet request at ten pages of labels
+const maxLabelItems = 210
+
+// GetLabelItems returns the name and asset tag of the given items for
+// printing labels, in the order asked for
+func (s *ItemService) GetLabelItems(ctx context.Context, ids []int) ([]models.Item, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("at least one item id is required")
+	}
+	if len(ids) > maxLabelItems {
+		return nil, fmt.Errorf("at most %d labels can be printed at once", maxLabelItems)
+	}
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, errors.New("invalid item id")
+		}
+	}
+
+	items, err := s.ItemRepo.FindLabels(ctx, s.UoW.DB, ids)
+	if err != nil {
+		log.Printf("Failed to get label items: %v", err.Error())
+		return nil, err
+	}
+	if len(items) != len(ids) {
+		return nil, errors.New("some of the items do not exist")
+	}
+	return items, nil
 }
 
-func (s *ItemService) UpdateItem(itemInput models.Item) (*models.Item, error) {
+func (s *ItemService) UpdateItem(ctx context.Context, itemInput models.Item) (*models.Item, error) {
 	if itemInput.ID == 0 {
 		return nil, errors.New("invalid id")
 	}
@@ -41,21 +179,263 @@ func (s *ItemService) UpdateItem(itemInput models.Item) (*models.Item, error) {
 		return nil, err
 	}
 
-	return s.ItemRepo.Update(&itemInput)
+	var item *models.Item
+	// Moves are recorded in the movement history, see ItemMovementService
+	if itemInput.LocationID != 0 {
+		return nil, errors.New("use the move endpoint to change the location of an item")
+	}
+
+	// The investment row is valued from the price, purchase date and
+	// depreciation settings, so it is updated along with them. Once
+	// depreciation has been posted those entries are the record, and the
+	// settings they were posted under can no longer change.
+	err = s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		posted, err := s.ItemInvestmentRepo.LockHasPostedEntries(ctx, tx, itemInput.ID)
+		if err != nil {
+			return err
+		}
+		before, err := s.ItemInvestmentRepo.FindDepreciationPolicy(ctx, tx, itemInput.ID)
+		if err != nil {
+			return err
+		}
+		item, err = s.ItemRepo.Update(ctx, tx, &itemInput)
+		if err != nil {
+			return err
+		}
+		after, err := s.ItemInvestmentRepo.FindDepreciationPolicy(ctx, tx, itemInput.ID)
+		if err != nil {
+			return err
+		}
+		if sameDepreciationPolicy(*before, *after) {
+			return nil
+		}
+		if posted {
+			return ErrDepreciationPosted
+		}
+
+		now := time.Now()
+		return s.ItemInvestmentRepo.ResetBasis(ctx, tx, &models.ItemInvestment{
+			ItemID:               itemInput.ID,
+			InitialPrice:         after.Cost,
+			CurrentValue:         BookValueAsOf(*after, now),
+			LastDepreciationDate: now,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// sameDepreciationPolicy reports whether two policies value an item the same
+func sameDepreciationPolicy(a, b models.DepreciationPolicy) bool {
+	return a.Method == b.Method && a.Cost == b.Cost && a.SalvageValue == b.SalvageValue &&
+		a.UsefulLifeYears == b.UsefulLifeYears && a.Rate == b.Rate && a.PurchaseDate.Equal(b.PurchaseDate)
 }
 
-func (s *ItemService) DeleteItem(id int) (string, error) {
+func (s *ItemService) DeleteItem(ctx context.Context, id int) error {
 	if id == 0 {
-		return "", errors.New("invalid id")
+		return errors.New("invalid id")
+	}
+	return s.ItemRepo.Delete(ctx, s.UoW.DB, id)
+}
+
+// RestoreItem brings back a deleted item, as long as its category is active
+func (s *ItemService) RestoreItem(ctx context.Context, id int) (*models.Item, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid id")
+	}
+
+	var item *models.Item
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		restored, err := s.ItemRepo.Restore(ctx, tx, id)
+		item = restored
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *ItemService) GetAllItems(ctx context.Context, filter models.ItemFilter) ([]models.Item, error) {
+	if filter.CategoryID < 0 {
+		return nil, errors.New("invalid category id")
+	}
+	if filter.LocationID < 0 {
+		return nil, errors.New("invalid location id")
+	}
+	if filter.Status != "" && filter.Status != models.StatusActive && filter.Status != models.StatusDeleted {
+		return nil, errors.New("invalid status")
+	}
+	if filter.State != "" && !filter.State.IsValid() {
+		return nil, errors.New("invalid lifecycle state")
+	}
+	return s.ItemRepo.FindAll(ctx, s.UoW.DB, filter)
+}
+
+// GetReplacementItems returns one page of the items flagged for replacement,
+// each with an explanation of which rule flagged it and any overdue
+// maintenance. It never writes; the flags are maintained by
+// RecalculateReplacementFlags.
+func (s *ItemService) GetReplacementItems(ctx context.Context, page, limit int) ([]models.Item, int, error) {
+	if page < 1 || limit < 1 {
+		return nil, 0, errors.New("invalid pagination")
+	}
+
+	candidates, total, err := s.ItemRepo.FindReplacementCandidates(ctx, s.UoW.DB, true, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(candidates) == 0 {
+		return []models.Item{}, total, nil
+	}
+
+	itemIDs := make([]int, 0, len(candidates))
+	for _, candidate := range candidates {
+		itemIDs = append(itemIDs, candidate.Item.ID)
+	}
+	overdue, err := s.PlanRepo.FindDue(ctx, s.UoW.DB, -1, itemIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	overdueByItem := make(map[int][]models.MaintenanceDue)
+	for _, due := range overdue {
+		overdueByItem[due.ItemID] = append(overdueByItem[due.ItemID], due)
+	}
+
+	now := time.Now()
+	items := make([]models.Item, 0, len(candidates))
+	for _, candidate := range candidates {
+		item := candidate.Item
+		status := EvaluateReplacement(item.TotalUsageDays, BookValueAsOf(candidate.Depreciation, now), candidate.Policy)
+		item.Replacement = &status
+		item.OverdueMaintenance = overdueByItem[item.ID]
+		items = append(items, item)
+	}
+	return items, total, nil
+}
+
+// RecalculateReplacementFlags re-evaluates every item against its policy and
+// updates the flags that changed. It returns the number of items updated.
+func (s *ItemService) RecalculateReplacementFlags(ctx context.Context) (int, error) {
+	updated := 0
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		candidates, _, err := s.ItemRepo.FindReplacementCandidates(ctx, tx, false, 0, 0)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, candidate := range candidates {
+			status := EvaluateReplacement(candidate.Item.TotalUsageDays, BookValueAsOf(candidate.Depreciation, now), candidate.Policy)
+			changed, err := s.ItemRepo.UpdateReplacementFlag(ctx, tx, candidate.Item.ID, len(status.TriggeredBy) > 0)
+			if err != nil {
+				return err
+			}
+			if changed {
+				updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
-	return s.ItemRepo.Delete(id)
+	return updated, nil
 }
 
-func (s *ItemService) GetAllItems() ([]models.Item, error) {
-	return s.ItemRepo.FindAll()
+// EvaluateReplacement checks an item's age and book value against its
+// replacement policy.
+func EvaluateReplacement(usageDays int, bookValue float64, policy models.ReplacementPolicy) models.ReplacementStatus {
+	status := models.ReplacementStatus{
+		Policy:    policy,
+		BookValue: bookValue,
+		DaysLeft:  policy.AfterDays - usageDays,
+	}
+
+	var reasons []string
+	if usageDays >= policy.AfterDays {
+		status.TriggeredBy = append(status.TriggeredBy, models.ReplacementRuleAge)
+		reasons = append(reasons, fmt.Sprintf("in use for %d days, replacement is due after %d days", usageDays, policy.AfterDays))
+	}
+	if policy.MinBookValue > 0 && bookValue <= policy.MinBookValue {
+		status.TriggeredBy = append(status.TriggeredBy, models.ReplacementRuleBookValue)
+		reasons = append(reasons, fmt.Sprintf("book value %.2f is at or below the minimum of %.2f", bookValue, policy.MinBookValue))
+	}
+
+	if len(reasons) == 0 {
+		status.Reason = fmt.Sprintf("%d days left before replacement is due", status.DaysLeft)
+	} else {
+		status.Reason = strings.Join(reasons, "; ")
+	}
+	return status
 }
 
-func (s *ItemService) GetReplacementItems() ([]models.Item, error) {
-	threshold := 100
-	return s.ItemRepo.ReplaceReminder(threshold)
+// ReplacementDueDate returns the date an item first meets its replacement
+// policy, either by age or by its book value dropping to the minimum, and
+// the rule that triggers first.
+func ReplacementDueDate(candidate models.ReplacementCandidate) (time.Time, string) {
+	purchaseDate := candidate.Depreciation.PurchaseDate
+	dueDate := purchaseDate.AddDate(0, 0, candidate.Policy.AfterDays)
+	rule := models.ReplacementRuleAge
+
+	if candidate.Policy.MinBookValue > 0 {
+		annual := AnnualDepreciation(candidate.Depreciation)
+		for month := 0; month <= len(annual)*12; month++ {
+			monthDate := purchaseDate.AddDate(0, month, 0)
+			if !monthDate.Before(dueDate) {
+				break
+			}
+			if valueAfterMonths(candidate.Depreciation, annual, month) <= candidate.Policy.MinBookValue {
+				return monthDate, models.ReplacementRuleBookValue
+			}
+		}
+	}
+	return dueDate, rule
+}
+
+// GetWarrantyExpiring returns the items whose warranty ends within the given
+// number of days, soonest first
+func (s *ItemService) GetWarrantyExpiring(ctx context.Context, withinDays int) ([]models.WarrantyExpiry, error) {
+	if withinDays < 0 {
+		return nil, errors.New("within cannot be negative")
+	}
+	return s.ItemRepo.FindWarrantyExpiring(ctx, s.UoW.DB, withinDays, false)
+}
+
+// NotifyExpiringWarranties sends one notification for each warranty ending
+// within the given number of days that has not been alerted for yet. A
+// warranty whose notification fails is tried again on the next run. It
+// returns the number of notifications sent.
+func (s *ItemService) NotifyExpiringWarranties(ctx context.Context, notifier notifications.Notifier, withinDays int) (int, error) {
+	expiries, err := s.ItemRepo.FindWarrantyExpiring(ctx, s.UoW.DB, withinDays, true)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, expiry := range expiries {
+		warranty := "The warranty"
+		if expiry.WarrantyProvider != "" {
+			warranty = "The " + expiry.WarrantyProvider + " warranty"
+		}
+		notification := notifications.Notification{
+			Subject: fmt.Sprintf("Warranty of %s ends in %d days", expiry.ItemName, expiry.DaysLeft),
+			Message: fmt.Sprintf("%s of item #%d %s ends on %s. Claim any outstanding repairs before then.",
+				warranty, expiry.ItemID, expiry.ItemName, expiry.WarrantyEndsOn.Format("2006-01-02")),
+			Data:   expiry,
+			SentAt: time.Now(),
+		}
+		if err := notifier.Notify(ctx, notification); err != nil {
+			log.Printf("Failed to send warranty notification for item %d: %v", expiry.ItemID, err.Error())
+			continue
+		}
+		if err := s.ItemRepo.MarkWarrantyAlerted(ctx, s.UoW.DB, expiry.ItemID, expiry.WarrantyEndsOn); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
 }
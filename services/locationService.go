@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/validations"
+)
+
+type LocationService struct {
+	UoW          *repositories.UnitOfWork
+	LocationRepo repositories.LocationRepository
+}
+
+func NewLocationService(uow *repositories.UnitOfWork, repo repositories.LocationRepository) *LocationService {
+	return &LocationService{UoW: uow, LocationRepo: repo}
+}
+
+func (s *LocationService) CreateLocation(ctx context.Context, locationInput models.Location) (*models.Location, error) {
+	if err := validations.ValidateLocationInput(&locationInput); err != nil {
+		return nil, err
+	}
+
+	var location *models.Location
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if err := s.checkHierarchy(ctx, tx, locationInput); err != nil {
+			return err
+		}
+		created, err := s.LocationRepo.Create(ctx, tx, &locationInput)
+		location = created
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to create location: %v", err.Error())
+		return nil, err
+	}
+	return location, nil
+}
+
+// UpdateLocation changes a location. The type and parent are checked against
+// the hierarchy the same way as on create.
+func (s *LocationService) UpdateLocation(ctx context.Context, locationInput models.Location) (*models.Location, error) {
+	if locationInput.ID <= 0 {
+		return nil, errors.New("location ID is required")
+	}
+	if locationInput.Type != "" && !locationInput.Type.IsValid() {
+		return nil, errors.New("location type must be site, building, floor or room")
+	}
+	if locationInput.ParentID != nil && *locationInput.ParentID < 0 {
+		return nil, errors.New("invalid parent location id")
+	}
+
+	var location *models.Location
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		current, err := s.LocationRepo.FindByID(ctx, tx, locationInput.ID)
+		if err != nil {
+			return err
+		}
+		// Check the location as it will look after the update
+		merged := *current
+		if locationInput.Type != "" {
+			merged.Type = locationInput.Type
+		}
+		if locationInput.ParentID != nil {
+			merged.ParentID = locationInput.ParentID
+		}
+		if err := s.checkHierarchy(ctx, tx, merged); err != nil {
+			return err
+		}
+
+		updated, err := s.LocationRepo.Update(ctx, tx, &locationInput)
+		location = updated
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to update location: %v", err.Error())
+		return nil, err
+	}
+	return location, nil
+}
+
+// DeleteLocation soft-deletes a location that holds no active items or sublocations
+func (s *LocationService) DeleteLocation(ctx context.Context, id int) error {
+	if id <= 0 {
+		return errors.New("invalid location id")
+	}
+
+	return s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		itemCount, sublocationCount, err := s.LocationRepo.CountUsage(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if itemCount > 0 || sublocationCount > 0 {
+			return fmt.Errorf("location still has %d active items and %d sublocations", itemCount, sublocationCount)
+		}
+		return s.LocationRepo.Delete(ctx, tx, id)
+	})
+}
+
+func (s *LocationService) GetLocationByID(ctx context.Context, id int) (*models.Location, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid location id")
+	}
+	return s.LocationRepo.FindByID(ctx, s.UoW.DB, id)
+}
+
+func (s *LocationService) GetAllLocations(ctx context.Context) ([]models.Location, error) {
+	return s.LocationRepo.FindAll(ctx, s.UoW.DB)
+}
+
+// GetLocationSummary counts the active items at a location and its
+// sublocations and totals their cost and book value as of today.
+func (s *LocationService) GetLocationSummary(ctx context.Context, id int) (*models.LocationSummary, error) {
+	location, err := s.GetLocationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := s.LocationRepo.FindDepreciationPolicies(ctx, s.UoW.DB, id)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.LocationSummary{Location: *location, AsOf: time.Now()}
+	for _, policy := range policies {
+		summary.ItemCount++
+		summary.TotalInvestment = roundMoney(summary.TotalInvestment + policy.Cost)
+		summary.BookValue = roundMoney(summary.BookValue + BookValueAsOf(policy, summary.AsOf))
+	}
+	return summary, nil
+}
+
+// checkHierarchy makes sure a location sits below a location of a higher
+// level, e.g. a floor in a building, and above its own sublocations. Since
+// the level strictly decreases down the tree, no cycle can form.
+func (s *LocationService) checkHierarchy(ctx context.Context, tx repositories.DBTX, location models.Location) error {
+	parentID := 0
+	if location.ParentID != nil {
+		parentID = *location.ParentID
+	}
+
+	if location.Type == models.LocationSite {
+		if parentID != 0 {
+			return errors.New("a site cannot have a parent location")
+		}
+	} else {
+		if parentID == 0 {
+			return fmt.Errorf("a %s needs a parent location", location.Type)
+		}
+		parent, err := s.LocationRepo.FindByID(ctx, tx, parentID)
+		if err != nil {
+			return errors.New("parent location does not exist")
+		}
+		if parent.Type.Rank() >= location.Type.Rank() {
+			return fmt.Errorf("a %s cannot be placed inside a %s", location.Type, parent.Type)
+		}
+	}
+
+	if location.ID == 0 {
+		return nil
+	}
+	childTypes, err := s.LocationRepo.FindChildTypes(ctx, tx, location.ID)
+	if err != nil {
+		return err
+	}
+	for _, childType := range childTypes {
+		if childType.Rank() <= location.Type.Rank() {
+			return fmt.Errorf("a %s cannot contain its existing %s sublocations", location.Type, childType)
+		}
+	}
+	return nil
+}
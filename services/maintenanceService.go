@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+type MaintenanceService struct {
+	UoW             *repositories.UnitOfWork
+	ItemRepo        repositories.ItemRepository
+	CategoryRepo    repositories.CategoryRepository
+	MaintenanceRepo repositories.MaintenanceRepository
+	PlanRepo        repositories.MaintenancePlanRepository
+}
+
+func NewMaintenanceService(uow *repositories.UnitOfWork, itemRepo repositories.ItemRepository, categoryRepo repositories.CategoryRepository,
+	maintenanceRepo repositories.MaintenanceRepository, planRepo repositories.MaintenancePlanRepository) *MaintenanceService {
+	return &MaintenanceService{UoW: uow, ItemRepo: itemRepo, CategoryRepo: categoryRepo, MaintenanceRepo: maintenanceRepo, PlanRepo: planRepo}
+}
+
+// CreateRecord logs maintenance done on an active item on behalf of userID.
+// A record without a date is taken to be done today. A record that names a
+// plan completes it, moving the plan's next due date for the item to one
+// interval after the record. A warranty claim must fall within the item's
+// warranty.
+func (s *MaintenanceService) CreateRecord(ctx context.Context, itemID, userID int, record models.MaintenanceRecord) (*models.MaintenanceRecord, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	if !record.Type.IsValid() {
+		return nil, errors.New("maintenance type must be preventive or corrective")
+	}
+	record.Description = strings.TrimSpace(record.Description)
+	if record.Description == "" {
+		return nil, errors.New("description is required")
+	}
+	if record.Cost < 0 {
+		return nil, errors.New("cost cannot be negative")
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if record.PerformedOn.IsZero() {
+		record.PerformedOn = today
+	}
+	if record.PerformedOn.After(today) {
+		return nil, errors.New("maintenance date cannot be in the future")
+	}
+	record.ItemID = itemID
+	record.RecordedBy = userID
+	record.Vendor = strings.TrimSpace(record.Vendor)
+	if record.PlanID < 0 {
+		return nil, errors.New("invalid plan id")
+	}
+
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if err := s.ItemRepo.Lock(ctx, tx, itemID); err != nil {
+			return err
+		}
+		if record.IsWarrantyClaim {
+			if err := s.checkWarranty(ctx, tx, itemID, record.PerformedOn); err != nil {
+				return err
+			}
+		}
+		if record.PlanID == 0 {
+			return s.MaintenanceRepo.Create(ctx, tx, &record)
+		}
+
+		applies, err := s.PlanRepo.AppliesTo(ctx, tx, record.PlanID, itemID)
+		if err != nil {
+			return err
+		}
+		if !applies {
+			return errors.New("maintenance plan does not cover this item")
+		}
+		if err := s.MaintenanceRepo.Create(ctx, tx, &record); err != nil {
+			return err
+		}
+		return s.PlanRepo.Complete(ctx, tx, &record)
+	})
+	if err != nil {
+		log.Printf("Failed to record maintenance of item %d: %v", itemID, err.Error())
+		return nil, err
+	}
+	return &record, nil
+}
+
+// checkWarranty makes sure the item was under warranty on the given date
+func (s *MaintenanceService) checkWarranty(ctx context.Context, tx repositories.DBTX, itemID int, date time.Time) error {
+	item, err := s.ItemRepo.FindByID(ctx, tx, itemID)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return errors.New("item does not exist")
+	}
+	if item.WarrantyEndsOn == nil {
+		return errors.New("item has no warranty to claim")
+	}
+	if date.After(*item.WarrantyEndsOn) {
+		return fmt.Errorf("item warranty ended on %s", item.WarrantyEndsOn.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// GetRecords returns the maintenance history of an item, most recent first
+func (s *MaintenanceService) GetRecords(ctx context.Context, itemID int) ([]models.MaintenanceRecord, error) {
+	if itemID <= 0 {
+		return nil, errors.New("invalid item id")
+	}
+	return s.MaintenanceRepo.FindByItemID(ctx, s.UoW.DB, itemID)
+}
+
+// CreatePlan adds a recurring maintenance plan for either an active item or
+// an active category, and schedules it for the items it covers right away.
+func (s *MaintenanceService) CreatePlan(ctx context.Context, plan models.MaintenancePlan) (*models.MaintenancePlan, error) {
+	plan.Name = strings.TrimSpace(plan.Name)
+	if plan.Name == "" {
+		return nil, errors.New("plan name is required")
+	}
+	if plan.IntervalDays < 1 {
+		return nil, errors.New("interval days must be at least 1")
+	}
+	if plan.ItemID < 0 || plan.CategoryID < 0 {
+		return nil, errors.New("invalid item or category id")
+	}
+	if (plan.ItemID == 0) == (plan.CategoryID == 0) {
+		return nil, errors.New("a plan needs either an item id or a category id")
+	}
+	plan.Description = strings.TrimSpace(plan.Description)
+
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		if plan.ItemID != 0 {
+			if err := s.ItemRepo.Lock(ctx, tx, plan.ItemID); err != nil {
+				return err
+			}
+		} else {
+			// Keep the category from being deleted until the plan is in
+			if err := s.CategoryRepo.LockCategory(ctx, tx, plan.CategoryID); err != nil {
+				return err
+			}
+		}
+		if err := s.PlanRepo.Create(ctx, tx, &plan); err != nil {
+			return err
+		}
+		_, _, err := s.PlanRepo.RefreshSchedules(ctx, tx)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to create maintenance plan: %v", err.Error())
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// DeletePlan removes a plan and its schedules
+func (s *MaintenanceService) DeletePlan(ctx context.Context, id int) error {
+	if id <= 0 {
+		return errors.New("invalid plan id")
+	}
+	return s.PlanRepo.Delete(ctx, s.UoW.DB, id)
+}
+
+func (s *MaintenanceService) GetPlans(ctx context.Context) ([]models.MaintenancePlan, error) {
+	return s.PlanRepo.FindAll(ctx, s.UoW.DB)
+}
+
+// GetDueList returns the maintenance due within the given number of days,
+// overdue maintenance first
+func (s *MaintenanceService) GetDueList(ctx context.Context, withinDays int) ([]models.MaintenanceDue, error) {
+	if withinDays < 0 {
+		return nil, errors.New("within cannot be negative")
+	}
+	return s.PlanRepo.FindDue(ctx, s.UoW.DB, withinDays, nil)
+}
+
+// RefreshSchedules brings the schedules in line with the plans, picking up
+// new items of a planned category and dropping deleted or recategorized ones.
+// It returns how many schedules were added and removed.
+func (s *MaintenanceService) RefreshSchedules(ctx context.Context) (int, int, error) {
+	var added, removed int
+	err := s.UoW.WithinTransaction(ctx, func(tx repositories.DBTX) error {
+		var err error
+		added, removed, err = s.PlanRepo.RefreshSchedules(ctx, tx)
+		return err
+	})
+	return added, removed, err
+}
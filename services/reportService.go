@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/repositories"
+)
+
+type ReportService struct {
+	UoW        *repositories.UnitOfWork
+	ReportRepo repositories.ReportRepository
+	ItemRepo   repositories.ItemRepository
+}
+
+func NewReportService(uow *repositories.UnitOfWork, repo repositories.ReportRepository, itemRepo repositories.ItemRepository) *ReportService {
+	return &ReportService{UoW: uow, ReportRepo: repo, ItemRepo: itemRepo}
+}
+
+// GetAssetRegister builds the fixed-asset register as of asOf, grouped by
+// category with subtotals. Items bought after asOf or disposed of by then
+// are left out.
+func (s *ReportService) GetAssetRegister(ctx context.Context, asOf time.Time) (*models.AssetRegister, error) {
+	records, err := s.ReportRepo.FindAssetRecords(ctx, s.UoW.DB)
+	if err != nil {
+		log.Printf("Failed to get asset records: %v", err.Error())
+		return nil, err
+	}
+
+	register := &models.AssetRegister{AsOf: asOf, Categories: []models.AssetRegisterCategory{}}
+	for _, record := range records {
+		policy := record.Policy
+		if policy.PurchaseDate.After(asOf) || (record.DisposedOn != nil && !record.DisposedOn.After(asOf)) {
+			continue
+		}
+
+		// Records are ordered by category, so a new category starts a new group
+		last := len(register.Categories) - 1
+		if last < 0 || register.Categories[last].CategoryID != policy.CategoryID {
+			register.Categories = append(register.Categories, models.AssetRegisterCategory{
+				CategoryID:   policy.CategoryID,
+				CategoryName: policy.CategoryName,
+			})
+			last++
+		}
+
+		netBookValue := BookValueAsOf(policy, asOf)
+		row := record.Row
+		row.ItemID = policy.ItemID
+		row.CategoryID = policy.CategoryID
+		row.CategoryName = policy.CategoryName
+		row.PurchaseDate = policy.PurchaseDate
+		row.Cost = policy.Cost
+		row.DepreciationMethod = policy.Method
+		row.UsefulLifeYears = policy.UsefulLifeYears
+		row.AccumulatedDepreciation = roundMoney(policy.Cost - netBookValue)
+		row.NetBookValue = netBookValue
+
+		category := &register.Categories[last]
+		category.Rows = append(category.Rows, row)
+		addToRegisterTotals(&category.Subtotal, row)
+		addToRegisterTotals(&register.GrandTotal, row)
+	}
+	return register, nil
+}
+
+func addToRegisterTotals(totals *models.AssetRegisterTotals, row models.AssetRegisterRow) {
+	totals.ItemCount++
+	totals.Cost = roundMoney(totals.Cost + row.Cost)
+	totals.AccumulatedDepreciation = roundMoney(totals.AccumulatedDepreciation + row.AccumulatedDepreciation)
+	totals.NetBookValue = roundMoney(totals.NetBookValue + row.NetBookValue)
+}
+
+// GetReplacementForecast lists the items that will reach their replacement
+// threshold in each of the next months, with the replacement cost estimated
+// from the original price grown by a yearly inflation rate (in percent).
+func (s *ReportService) GetReplacementForecast(ctx context.Context, months int, inflationRate float64) (*models.ReplacementForecast, error) {
+	if months < 1 || months > 120 {
+		return nil, errors.New("months must be between 1 and 120")
+	}
+	if math.IsNaN(inflationRate) || math.IsInf(inflationRate, 0) || inflationRate <= -100 {
+		return nil, errors.New("inflation rate must be greater than -100")
+	}
+
+	candidates, _, err := s.ItemRepo.FindReplacementCandidates(ctx, s.UoW.DB, false, 0, 0)
+	if err != nil {
+		log.Printf("Failed to get replacement candidates: %v", err.Error())
+		return nil, err
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	firstMonth, _ := MonthBounds(from)
+	forecast := &models.ReplacementForecast{
+		From:          from,
+		To:            firstMonth.AddDate(0, months, -1),
+		Months:        months,
+		InflationRate: inflationRate,
+		ByCategory:    []models.ForecastCategory{},
+	}
+	for month := 0; month < months; month++ {
+		forecast.ByMonth = append(forecast.ByMonth, models.ForecastMonth{
+			Month: firstMonth.AddDate(0, month, 0).Format("2006-01"),
+			Items: []models.ForecastItem{},
+		})
+	}
+
+	// Keyed by id, as category names repeat under different parents
+	categoryIndex := make(map[int]int)
+	for _, candidate := range candidates {
+		dueDate, rule := ReplacementDueDate(candidate)
+		// Items already due are listed by /api/items/need-replacement
+		if dueDate.Before(from) || dueDate.After(forecast.To) {
+			continue
+		}
+
+		years := dueDate.Sub(candidate.Depreciation.PurchaseDate).Hours() / 24 / 365.25
+		estimatedCost := roundMoney(candidate.Item.Price * math.Pow(1+inflationRate/100, years))
+		monthIndex := (dueDate.Year()-firstMonth.Year())*12 + int(dueDate.Month()) - int(firstMonth.Month())
+
+		month := &forecast.ByMonth[monthIndex]
+		month.Items = append(month.Items, models.ForecastItem{
+			ItemID:          candidate.Item.ID,
+			ItemName:        candidate.Item.Name,
+			CategoryName:    candidate.Item.CategoryName,
+			ReplacementDate: dueDate,
+			TriggeredBy:     rule,
+			OriginalPrice:   candidate.Item.Price,
+			EstimatedCost:   estimatedCost,
+		})
+		month.ItemCount++
+		month.EstimatedCost = roundMoney(month.EstimatedCost + estimatedCost)
+
+		index, ok := categoryIndex[candidate.Item.CategoryID]
+		if !ok {
+			index = len(forecast.ByCategory)
+			categoryIndex[candidate.Item.CategoryID] = index
+			forecast.ByCategory = append(forecast.ByCategory, models.ForecastCategory{
+				CategoryID:   candidate.Item.CategoryID,
+				CategoryName: candidate.Item.CategoryName,
+				CategoryPath: candidate.Item.CategoryPath,
+			})
+		}
+		forecast.ByCategory[index].ItemCount++
+		forecast.ByCategory[index].EstimatedCost = roundMoney(forecast.ByCategory[index].EstimatedCost + estimatedCost)
+
+		forecast.ItemCount++
+		forecast.TotalEstimatedCost = roundMoney(forecast.TotalEstimatedCost + estimatedCost)
+	}
+
+	sort.Slice(forecast.ByCategory, func(a, b int) bool {
+		return forecast.ByCategory[a].CategoryPath < forecast.ByCategory[b].CategoryPath
+	})
+	return forecast, nil
+}
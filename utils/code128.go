@@ -0,0 +1,61 @@
+package utils
+
+import "fmt"
+
+// code128Patterns holds the bar and space widths of every Code 128 symbol,
+// starting with a bar. 103 to 105 are the start codes and 106 is the stop.
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128Stop   = 106
+)
+
+// Code128QuietZone is the blank margin, in modules, a scanner needs on
+// either side of a barcode
+const Code128QuietZone = 10
+
+// Code128Modules encodes text as a Code 128 (code set B) barcode and returns
+// its modules from left to right, true for a bar. The quiet zone is not
+// included. Only printable ASCII can be encoded.
+func Code128Modules(text string) ([]bool, error) {
+	if text == "" {
+		return nil, fmt.Errorf("nothing to encode")
+	}
+
+	symbols := []int{code128StartB}
+	checksum := code128StartB
+	for i, r := range text {
+		if r < 32 || r > 126 {
+			return nil, fmt.Errorf("character %q cannot be encoded in Code 128", r)
+		}
+		value := int(r) - 32
+		symbols = append(symbols, value)
+		checksum += value * (i + 1)
+	}
+	symbols = append(symbols, checksum%103, code128Stop)
+
+	var modules []bool
+	for _, symbol := range symbols {
+		bar := true
+		for _, width := range code128Patterns[symbol] {
+			for n := 0; n < int(width-'0'); n++ {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+	return modules, nil
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+// Size of the single labels in pixels (PNG) or user units (SVG)
+const (
+	labelModuleSize = 2
+	labelBarHeight  = 60
+	labelMargin     = 10
+	labelTextScale  = 2
+)
+
+// labelGlyphs is a 5x7 bitmap font covering the characters of asset tags
+var labelGlyphs = map[rune][7]string{
+	'0': {"01110", "10001", "10011", "10101", "11001", "10001", "01110"},
+	'1': {"00100", "01100", "00100", "00100", "00100", "00100", "01110"},
+	'2': {"01110", "10001", "00001", "00010", "00100", "01000", "11111"},
+	'3': {"11111", "00010", "00100", "00010", "00001", "10001", "01110"},
+	'4': {"00010", "00110", "01010", "10010", "11111", "00010", "00010"},
+	'5': {"11111", "10000", "11110", "00001", "00001", "10001", "01110"},
+	'6': {"00110", "01000", "10000", "11110", "10001", "10001", "01110"},
+	'7': {"11111", "00001", "00010", "00100", "01000", "01000", "01000"},
+	'8': {"01110", "10001", "10001", "01110", "10001", "10001", "01110"},
+	'9': {"01110", "10001", "10001", "01111", "00001", "00010", "01100"},
+	'A': {"01110", "10001", "10001", "11111", "10001", "10001", "10001"},
+	'B': {"11110", "10001", "10001", "11110", "10001", "10001", "11110"},
+	'C': {"01110", "10001", "10000", "10000", "10000", "10001", "01110"},
+	'D': {"11100", "10010", "10001", "10001", "10001", "10010", "11100"},
+	'E': {"11111", "10000", "10000", "11110", "10000", "10000", "11111"},
+	'F': {"11111", "10000", "10000", "11110", "10000", "10000", "10000"},
+	'G': {"01110", "10001", "10000", "10111", "10001", "10001", "01111"},
+	'H': {"10001", "10001", "10001", "11111", "10001", "10001", "10001"},
+	'I': {"01110", "00100", "00100", "00100", "00100", "00100", "01110"},
+	'J': {"00111", "00010", "00010", "00010", "00010", "10010", "01100"},
+	'K': {"10001", "10010", "10100", "11000", "10100", "10010", "10001"},
+	'L': {"10000", "10000", "10000", "10000", "10000", "10000", "11111"},
+	'M': {"10001", "11011", "10101", "10101", "10001", "10001", "10001"},
+	'N': {"10001", "10001", "11001", "10101", "10011", "10001", "10001"},
+	'O': {"01110", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'P': {"11110", "10001", "10001", "11110", "10000", "10000", "10000"},
+	'Q': {"01110", "10001", "10001", "10001", "10101", "10010", "01101"},
+	'R': {"11110", "10001", "10001", "11110", "10100", "10010", "10001"},
+	'S': {"01111", "10000", "10000", "01110", "00001", "00001", "11110"},
+	'T': {"11111", "00100", "00100", "00100", "00100", "00100", "00100"},
+	'U': {"10001", "10001", "10001", "10001", "10001", "10001", "01110"},
+	'V': {"10001", "10001", "10001", "10001", "10001", "01010", "00100"},
+	'W': {"10001", "10001", "10001", "10101", "10101", "10101", "01010"},
+	'X': {"10001", "10001", "01010", "00100", "01010", "10001", "10001"},
+	'Y': {"10001", "10001", "01010", "00100", "00100", "00100", "00100"},
+	'Z': {"11111", "00001", "00010", "00100", "01000", "10000", "11111"},
+	'-': {"00000", "00000", "00000", "11111", "00000", "00000", "00000"},
+}
+
+// WriteLabelPNG writes a Code 128 barcode of an asset tag with the tag
+// printed underneath
+func WriteLabelPNG(w io.Writer, tag string) error {
+	modules, err := Code128Modules(tag)
+	if err != nil {
+		return err
+	}
+
+	width := (len(modules) + 2*Code128QuietZone) * labelModuleSize
+	textTop := labelMargin + labelBarHeight + 8
+	height := textTop + 7*labelTextScale + labelMargin
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	fill := func(x, y, w, h int) {
+		for py := y; py < y+h; py++ {
+			for px := x; px < x+w; px++ {
+				img.SetGray(px, py, color.Gray{Y: 0})
+			}
+		}
+	}
+
+	for i, bar := range modules {
+		if bar {
+			fill((Code128QuietZone+i)*labelModuleSize, labelMargin, labelModuleSize, labelBarHeight)
+		}
+	}
+
+	// Each glyph is 5 dots wide with a dot of spacing
+	text := strings.ToUpper(tag)
+	x := (width - len(text)*6*labelTextScale) / 2
+	for _, r := range text {
+		for row, line := range labelGlyphs[r] {
+			for col, dot := range line {
+				if dot == '1' {
+					fill(x+col*labelTextScale, textTop+row*labelTextScale, labelTextScale, labelTextScale)
+				}
+			}
+		}
+		x += 6 * labelTextScale
+	}
+	return png.Encode(w, img)
+}
+
+// WriteLabelSVG writes a Code 128 barcode of an asset tag with the tag
+// printed underneath
+func WriteLabelSVG(w io.Writer, tag string) error {
+	modules, err := Code128Modules(tag)
+	if err != nil {
+		return err
+	}
+
+	width := (len(modules) + 2*Code128QuietZone) * labelModuleSize
+	textTop := labelMargin + labelBarHeight + 8
+	height := textTop + 7*labelTextScale + labelMargin
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, width, height)
+	for _, run := range barRuns(modules) {
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#000"/>`,
+			(Code128QuietZone+run[0])*labelModuleSize, labelMargin, run[1]*labelModuleSize, labelBarHeight)
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`,
+		width/2, textTop+7*labelTextScale, 8*labelTextScale, escapeXML(tag))
+	b.WriteString(`</svg>`)
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// barRuns merges neighbouring bar modules into [start, width] pairs
+func barRuns(modules []bool) [][2]int {
+	var runs [][2]int
+	for i := 0; i < len(modules); i++ {
+		if !modules[i] {
+			continue
+		}
+		start := i
+		for i+1 < len(modules) && modules[i+1] {
+			i++
+		}
+		runs = append(runs, [2]int{start, i - start + 1})
+	}
+	return runs
+}
+
+// Layout of the label sheet: A4 with 3 by 7 labels of 63.5 x 38.1 mm, the
+// common L7160 stock. Sizes are in points.
+const (
+	mmToPoints         = 72 / 25.4
+	sheetWidth         = 210 * mmToPoints
+	sheetHeight        = 297 * mmToPoints
+	sheetColumns       = 3
+	sheetRows          = 7
+	sheetLabelWidth    = 63.5 * mmToPoints
+	sheetLabelHeight   = 38.1 * mmToPoints
+	sheetLeftMargin    = 7.2 * mmToPoints
+	sheetTopMargin     = 15.15 * mmToPoints
+	sheetColumnPitch   = 66.04 * mmToPoints
+	sheetLabelPadding  = 3 * mmToPoints
+	sheetBarcodeHeight = 16 * mmToPoints
+)
+
+// WriteLabelSheetPDF writes printable label sheets for items, each label
+// holding the item name, a Code 128 barcode of its asset tag and the tag
+func WriteLabelSheetPDF(w io.Writer, items []models.Item) error {
+	document := NewPDFDocument(sheetWidth, sheetHeight)
+	var page *PDFPage
+	for i, item := range items {
+		modules, err := Code128Modules(item.AssetTag)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", item.ID, err)
+		}
+
+		slot := i % (sheetColumns * sheetRows)
+		if slot == 0 {
+			page = document.AddPage()
+		}
+		left := sheetLeftMargin + float64(slot%sheetColumns)*sheetColumnPitch
+		top := sheetHeight - sheetTopMargin - float64(slot/sheetColumns)*sheetLabelHeight
+		innerWidth := sheetLabelWidth - 2*sheetLabelPadding
+
+		// Helvetica averages a little over half the font size per character
+		const nameSize = 8
+		name := item.Name
+		if maxChars := int(innerWidth / (nameSize * 0.55)); len([]rune(name)) > maxChars {
+			name = string([]rune(name)[:maxChars-3]) + "..."
+		}
+		page.Text(left+sheetLabelPadding, top-sheetLabelPadding-nameSize, nameSize, name)
+
+		moduleWidth := innerWidth / float64(len(modules)+2*Code128QuietZone)
+		barsLeft := left + sheetLabelPadding + Code128QuietZone*moduleWidth
+		barsBottom := top - sheetLabelPadding - nameSize - 3 - sheetBarcodeHeight
+		for _, run := range barRuns(modules) {
+			page.Rect(barsLeft+float64(run[0])*moduleWidth, barsBottom, float64(run[1])*moduleWidth, sheetBarcodeHeight)
+		}
+
+		const tagSize = 9
+		page.Text(barsLeft, barsBottom-tagSize-2, tagSize, item.AssetTag)
+	}
+	if len(items) == 0 {
+		document.AddPage()
+	}
+	return document.Write(w)
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// PDFDocument is a minimal PDF writer for pages of filled rectangles and
+// Helvetica text. Coordinates are in points from the bottom-left corner.
+type PDFDocument struct {
+	Width  float64
+	Height float64
+	pages  []*PDFPage
+}
+
+// PDFPage holds the drawing operators of one page
+type PDFPage struct {
+	content bytes.Buffer
+}
+
+func NewPDFDocument(width, height float64) *PDFDocument {
+	return &PDFDocument{Width: width, Height: height}
+}
+
+func (d *PDFDocument) AddPage() *PDFPage {
+	page := &PDFPage{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Rect draws a black rectangle with its bottom-left corner at x, y
+func (p *PDFPage) Rect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", pdfNumber(x), pdfNumber(y), pdfNumber(width), pdfNumber(height))
+}
+
+// Text draws text in Helvetica with its baseline starting at x, y
+func (p *PDFPage) Text(x, y, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n", pdfNumber(size), pdfNumber(x), pdfNumber(y), pdfString(text))
+}
+
+// Write writes the document. Objects 1 to 3 are the catalog, the page tree
+// and the font; each page then takes two objects, itself and its content.
+func (d *PDFDocument) Write(w io.Writer) error {
+	var buf bytes.Buffer
+	offsets := []int{}
+	startObject := func() {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	startObject()
+	buf.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	startObject()
+	buf.WriteString("<< /Type /Pages /Kids [")
+	for i := range d.pages {
+		fmt.Fprintf(&buf, " %d 0 R", 4+i*2)
+	}
+	fmt.Fprintf(&buf, " ] /Count %d /MediaBox [0 0 %s %s] >>\nendobj\n", len(d.pages), pdfNumber(d.Width), pdfNumber(d.Height))
+
+	startObject()
+	buf.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\nendobj\n")
+
+	for i, page := range d.pages {
+		startObject()
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>\nendobj\n", 5+i*2)
+
+		startObject()
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n", page.content.Len())
+		buf.Write(page.content.Bytes())
+		buf.WriteString("endstream\nendobj\n")
+	}
+
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func pdfNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// pdfString escapes text for a PDF string literal. Characters outside
+// Latin-1 cannot be shown by the standard font and become question marks.
+func pdfString(text string) string {
+	var buf bytes.Buffer
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(byte(r))
+		case r < 32 || (r >= 127 && r < 160) || r > 255:
+			buf.WriteByte('?')
+		default:
+			buf.WriteByte(byte(r))
+		}
+	}
+	return buf.String()
+}
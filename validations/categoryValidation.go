@@ -2,10 +2,13 @@ package validations
 
 import (
 	"errors"
+	"regexp"
 
 	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
 )
 
+var tagPrefixPattern = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+
 func ValidateCategoryInput(categoryInput *models.Category) error {
 	if categoryInput.Name == "" {
 		return errors.New("name is required")
@@ -13,5 +16,26 @@ func ValidateCategoryInput(categoryInput *models.Category) error {
 	if categoryInput.Description == "" {
 		return errors.New("description is required")
 	}
+	if categoryInput.ParentID != nil && *categoryInput.ParentID < 0 {
+		return errors.New("invalid parent category id")
+	}
+	if categoryInput.DepreciationMethod != "" && !categoryInput.DepreciationMethod.IsValid() {
+		return errors.New("invalid depreciation method")
+	}
+	if categoryInput.UsefulLifeYears < 0 {
+		return errors.New("useful life cannot be negative")
+	}
+	if categoryInput.SalvageValue < 0 {
+		return errors.New("salvage value cannot be negative")
+	}
+	if categoryInput.ReplacementAfterDays < 0 {
+		return errors.New("replacement after days cannot be negative")
+	}
+	if categoryInput.ReplacementMinBookValue < 0 {
+		return errors.New("replacement minimum book value cannot be negative")
+	}
+	if categoryInput.TagPrefix != "" && !tagPrefixPattern.MatchString(categoryInput.TagPrefix) {
+		return errors.New("tag prefix must be 2 to 10 uppercase letters or digits")
+	}
 	return nil
 }
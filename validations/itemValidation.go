@@ -24,5 +24,41 @@ func ValidateItemInput(item models.Item) error {
 		log.Println("item purchase date is required")
 		return errors.New("item purchase date is required")
 	}
+	if item.ReplacementAfterDays < 0 {
+		log.Printf("invalid replacement after days %d", item.ReplacementAfterDays)
+		return errors.New("replacement after days cannot be negative")
+	}
+	if item.ReplacementMinBookValue < 0 {
+		log.Printf("invalid replacement minimum book value %.2f", item.ReplacementMinBookValue)
+		return errors.New("replacement minimum book value cannot be negative")
+	}
+	if item.WarrantyEndsOn != nil && item.WarrantyEndsOn.Before(item.PurchaseDate) {
+		log.Printf("invalid warranty end date %s", item.WarrantyEndsOn.Format("2006-01-02"))
+		return errors.New("warranty cannot end before the purchase date")
+	}
+	return ValidateDepreciationInput(item)
+}
+
+func ValidateDepreciationInput(item models.Item) error {
+	if item.DepreciatedRate < 0 || item.DepreciatedRate > 100 {
+		log.Printf("invalid depreciated rate %d", item.DepreciatedRate)
+		return errors.New("depreciated rate must be between 0 and 100")
+	}
+	if item.DepreciationMethod != "" && !item.DepreciationMethod.IsValid() {
+		log.Printf("invalid depreciation method %s", item.DepreciationMethod)
+		return errors.New("invalid depreciation method")
+	}
+	if item.DepreciationMethod == models.DecliningBalance && item.DepreciatedRate == 0 {
+		log.Println("depreciated rate is required for declining balance")
+		return errors.New("depreciated rate is required for declining balance")
+	}
+	if item.UsefulLifeYears < 0 {
+		log.Printf("invalid useful life %d", item.UsefulLifeYears)
+		return errors.New("useful life cannot be negative")
+	}
+	if item.SalvageValue < 0 || item.SalvageValue > item.Price {
+		log.Printf("invalid salvage value %.2f", item.SalvageValue)
+		return errors.New("salvage value must be between 0 and the item price")
+	}
 	return nil
 }
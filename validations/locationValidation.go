@@ -0,0 +1,20 @@
+package validations
+
+import (
+	"errors"
+
+	"github.com/Safiramdhn/project-app-inventaris-golang-safira/models"
+)
+
+func ValidateLocationInput(locationInput *models.Location) error {
+	if locationInput.Name == "" {
+		return errors.New("name is required")
+	}
+	if !locationInput.Type.IsValid() {
+		return errors.New("location type must be site, building, floor or room")
+	}
+	if locationInput.ParentID != nil && *locationInput.ParentID < 0 {
+		return errors.New("invalid parent location id")
+	}
+	return nil
+}